@@ -0,0 +1,32 @@
+package api
+
+import (
+	"github.com/boothgames/nightfury/log"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func readGameAnalytics(c *gin.Context) {
+	analytics, err := nightfury.NewAnalyticsFromRepo(scopedRepository(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, analytics)
+}
+
+// exportGameAnalytics writes the analytics as csv, one row per game
+func exportGameAnalytics(c *gin.Context) {
+	analytics, err := nightfury.NewAnalyticsFromRepo(scopedRepository(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="games.csv"`)
+	c.Status(http.StatusOK)
+	if err := analytics.WriteCSV(c.Writer); err != nil {
+		log.Error(err)
+	}
+}
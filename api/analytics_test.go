@@ -0,0 +1,46 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGameAnalytics(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	repository := db.DefaultRepository()
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+	_ = nightfury.NewClient("kiosk-1", false).Save(repository)
+	_, _ = nightfury.History{Client: "kiosk-1"}.Append(repository,
+		nightfury.GameplayEvent{Type: nightfury.GameStarted, Game: "snakes", Status: nightfury.InProgress, At: at},
+		nightfury.GameplayEvent{Type: nightfury.GameCompleted, Game: "snakes", Status: nightfury.Completed, At: at.Add(time.Minute)},
+	)
+
+	t.Run("should report the analytics of the games", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/analytics/games", nil)
+
+		actual := nightfury.Analytics{}
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, []nightfury.GameAnalytics{{Game: "snakes", Attempts: 1, Completed: 1, CompletionRate: 1, MedianDuration: 60, P90Duration: 60}}, actual.Games)
+		assert.Equal(t, []nightfury.FunnelStep{{Game: "snakes", Reached: 1, Completed: 1}}, actual.Funnel)
+	})
+
+	t.Run("should export the analytics as csv", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/analytics/games/export", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "text/csv", response.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(response.Body.String()), "\n")
+		if assert.Len(t, lines, 2) {
+			assert.Equal(t, "snakes,1,1,0,1,0,60,60,1,0,0", lines[1])
+		}
+	})
+}
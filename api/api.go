@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/boothgames/nightfury/api/events"
 	"github.com/boothgames/nightfury/api/socket"
 	"github.com/boothgames/nightfury/log"
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,8 @@ func init() {
 
 // Bind binds the route to gin
 func Bind(engine *gin.Engine) {
+	engine.Use(assignRequestID, logAccess, recordRequestDuration)
+	engine.GET("/metrics", exposeMetrics)
 	engine.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"message": "pong",
@@ -34,13 +37,109 @@ func Bind(engine *gin.Engine) {
 		v1.PUT("/hints/:id", populateHint, updateHint)
 		v1.DELETE("/hints/:id", populateHint, deleteHint)
 
+		v1.GET("/teams", listTeams)
+		v1.POST("/teams", createTeam)
+		v1.GET("/teams/:id", populateTeam, readTeam)
+		v1.DELETE("/teams/:id", populateTeam, deleteTeam)
+
+		v1.GET("/races", listRaces)
+		v1.POST("/races", createRace)
+		v1.GET("/races/:id", populateRace, readRace)
+		v1.POST("/races/:id/start", startRace)
+
 		v1.GET("/clients", listClients)
+		v1.GET("/clients/:id", populateClient, readClient)
+		v1.DELETE("/clients/:id", populateClient, deleteClient)
+		v1.GET("/clients/:id/history", populateClient, readClientHistory)
+		v1.PUT("/clients/:id/profile", editProfile)
+		v1.POST("/clients/:id/register", registerPlayer)
+		v1.GET("/clients/:id/games", populateClient, readAssignedGames)
+		v1.PUT("/clients/:id/games", assignGames)
+		v1.POST("/clients/:id/pause", pauseClient)
+		v1.POST("/clients/:id/resume", resumeClient)
+		v1.GET("/clients/:id/tokens", requireProvisioningKey, issueTokens)
+		v1.GET("/clients/:id/events", events.HandleClientEvents)
+		v1.GET("/audit", listAuditEntries)
+		v1.GET("/audit/export", exportAuditEntries)
+		v1.GET("/analytics/games", readGameAnalytics)
+		v1.GET("/analytics/games/export", exportGameAnalytics)
+		v1.GET("/players", listPlayers)
+		v1.GET("/players/export", exportPlayers)
+		v1.DELETE("/players/:id", erasePlayer)
+		v1.GET("/prizes", listPrizes)
+		v1.POST("/prizes", createPrize)
+		v1.GET("/prizes/:id", populatePrize, readPrize)
+		v1.PUT("/prizes/:id", populatePrize, updatePrize)
+		v1.DELETE("/prizes/:id", populatePrize, deletePrize)
+		v1.GET("/rewards", listRewards)
+		v1.POST("/rewards/redeem", redeemReward)
+		v1.GET("/webhooks", listWebhooks)
+		v1.POST("/webhooks", createWebhook)
+		v1.GET("/webhooks/:id", populateWebhook, readWebhook)
+		v1.PUT("/webhooks/:id", populateWebhook, updateWebhook)
+		v1.DELETE("/webhooks/:id", populateWebhook, deleteWebhook)
+		v1.GET("/webhooks/:id/deliveries", populateWebhook, listDeliveries)
+		v1.GET("/events", events.HandleEvents)
+		v1.GET("/conferences", listEvents)
+		v1.POST("/conferences", createEvent)
+	}
+
+	event := v1.Group("/events/:event", populateEvent)
+	{
+		event.GET("", readEvent)
+		event.POST("/activate", activateEvent)
+
+		event.POST("/bulk/games", uploadGames)
+		event.POST("/bulk/hints", uploadHints)
+
+		event.GET("/games", listGames)
+		event.POST("/games", createGame)
+		event.GET("/games/:id", populateGame, readGame)
+		event.PUT("/games/:id", populateGame, updateGame)
+		event.DELETE("/games/:id", populateGame, deleteGame)
+
+		event.GET("/hints", listHints)
+		event.POST("/hints", createHint)
+		event.GET("/hints/:id", populateHint, readHint)
+		event.PUT("/hints/:id", populateHint, updateHint)
+		event.DELETE("/hints/:id", populateHint, deleteHint)
+
+		event.GET("/clients", listClients)
+		event.GET("/clients/:id", populateClient, readClient)
+		event.DELETE("/clients/:id", populateClient, deleteClient)
+		event.GET("/clients/:id/history", populateClient, readClientHistory)
+		event.PUT("/clients/:id/profile", editProfile)
+		event.PUT("/clients/:id/games", assignGames)
+		event.GET("/teams", listTeams)
+		event.POST("/teams", createTeam)
+		event.GET("/teams/:id", populateTeam, readTeam)
+		event.DELETE("/teams/:id", populateTeam, deleteTeam)
+		event.GET("/races", listRaces)
+		event.POST("/races", createRace)
+		event.GET("/races/:id", populateRace, readRace)
+		event.GET("/audit", listAuditEntries)
+		event.GET("/audit/export", exportAuditEntries)
+		event.GET("/analytics/games", readGameAnalytics)
+		event.GET("/analytics/games/export", exportGameAnalytics)
+
+		event.GET("/players", listPlayers)
+		event.GET("/players/export", exportPlayers)
+		event.DELETE("/players/:id", erasePlayer)
+
+		event.GET("/prizes", listPrizes)
+		event.POST("/prizes", createPrize)
+		event.GET("/prizes/:id", populatePrize, readPrize)
+		event.PUT("/prizes/:id", populatePrize, updatePrize)
+		event.DELETE("/prizes/:id", populatePrize, deletePrize)
+		event.GET("/rewards", listRewards)
+		event.POST("/rewards/redeem", redeemReward)
 	}
 
 	wsV1 := engine.Group("/ws/v1")
 	{
 		wsV1.GET("clients/:id", socket.HandleClients)
 		wsV1.GET("clients/:id/games/:name", socket.HandleGames)
+		wsV1.GET("clients/:id/spectators", socket.HandleSpectators)
 	}
 	socket.BindSocket()
 }
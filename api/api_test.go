@@ -24,6 +24,7 @@ func TestPing(t *testing.T) {
 }
 
 func teardownTestContext(t *testing.T) {
+	db.ActivateScope("")
 	_ = db.Close()
 	_ = os.RemoveAll(testDBFileName)
 }
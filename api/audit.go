@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/log"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gin-gonic/gin"
+	"net"
+	"net/http"
+	"time"
+)
+
+// apiActor returns the caller of the rest api as the actor, identified by its address and request id.
+// The address is the one of the connection, as forwarding headers can be set by anyone
+func apiActor(c *gin.Context) nightfury.Actor {
+	address, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		address = c.Request.RemoteAddr
+	}
+	return nightfury.Actor{Type: nightfury.APIActor, ID: address, RequestID: c.GetString(log.RequestIDField)}
+}
+
+// audit appends the change made through the api to the audit log, the change being already made
+// a failure to audit it is only logged
+func audit(c *gin.Context, action, entity, name string, before, after interface{}) {
+	entry, err := nightfury.NewAuditEntry(time.Now(), apiActor(c), action, entity, name, before, after)
+	if entity == "client" {
+		entry = entry.ForClient(name)
+	}
+	if err == nil {
+		err = entry.Save(scopedRepository(c))
+	}
+	if err != nil {
+		log.WithFields(log.Fields{log.RequestIDField: c.GetString(log.RequestIDField)}).
+			Errorf("cannot audit %v of %v %v. Error: %v", action, entity, name, err)
+	}
+}
+
+// existing returns the model found in db, nil if it does not exist yet
+func existing(fetchFn func(db.Repository, string) (interface{}, error), repo db.Repository, name string) interface{} {
+	model, err := fetchFn(repo, name)
+	if err != nil {
+		return nil
+	}
+	return model
+}
+
+func existingGame(repo db.Repository, name string) (interface{}, error) {
+	return nightfury.NewGameFromRepoWithName(repo, name)
+}
+
+func existingHint(repo db.Repository, name string) (interface{}, error) {
+	return nightfury.NewHintFromRepoWithName(repo, name)
+}
+
+func auditFilter(c *gin.Context) (nightfury.AuditFilter, bool) {
+	filter := nightfury.AuditFilter{Client: c.Query("client")}
+	if since := c.Query("since"); since != "" {
+		at, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "since should be an RFC 3339 time, e.g. 2019-10-01T10:00:00Z"})
+			return filter, false
+		}
+		filter.Since = at
+	}
+	return filter, true
+}
+
+func listAuditEntries(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+	entries, err := nightfury.NewAuditEntriesFromRepo(scopedRepository(c), filter)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// exportAuditEntries writes the entries as json lines, one entry per line
+func exportAuditEntries(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+	entries, err := nightfury.NewAuditEntriesFromRepo(scopedRepository(c), filter)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			log.Error(err)
+			return
+		}
+	}
+}
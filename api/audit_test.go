@@ -0,0 +1,118 @@
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+
+	game := nightfury.Game{Name: "snakes", Instruction: "eat", Type: "web"}
+	_ = performRequest(router, "POST", "/v1/games", game)
+	game.Instruction = "eat apples"
+	_ = performRequest(router, "PUT", "/v1/games/snakes", game)
+	_ = performRequest(router, "DELETE", "/v1/games/snakes", nil)
+	_ = performRequest(router, "POST", "/v1/bulk/hints", []nightfury.Hint{{Title: "hint", Tag: []string{"snakes"}, Content: "content", Takeaway: "takeaway"}})
+
+	t.Run("should list the changes oldest first", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/audit", nil)
+
+		var actual []nightfury.AuditEntry
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusOK, response.Code)
+		if assert.Len(t, actual, 4) {
+			assert.Equal(t, []string{"create", "update", "delete", "upload"},
+				[]string{actual[0].Action, actual[1].Action, actual[2].Action, actual[3].Action})
+			assert.Equal(t, nightfury.APIActor, actual[0].Actor.Type)
+			assert.NotEmpty(t, actual[0].Actor.RequestID)
+			assert.Nil(t, actual[0].Before)
+			assert.Contains(t, string(actual[1].Before), `"instruction":"eat"`)
+			assert.Contains(t, string(actual[1].After), `"instruction":"eat apples"`)
+			assert.Nil(t, actual[2].After)
+			assert.Equal(t, "hint", actual[3].Entity)
+		}
+	})
+
+	t.Run("should filter the changes by client", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/audit?client=kiosk-1", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "[]", response.Body.String())
+	})
+
+	t.Run("should fail for an invalid since", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/audit?since=yesterday", nil)
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("should export the changes as json lines", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/audit/export?since=2019-10-01T10:00:00Z", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "application/x-ndjson", response.Header().Get("Content-Type"))
+		lines := 0
+		scanner := bufio.NewScanner(bytes.NewReader(response.Body.Bytes()))
+		for scanner.Scan() {
+			entry := nightfury.AuditEntry{}
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+			lines++
+		}
+		assert.Equal(t, 4, lines)
+	})
+
+	t.Run("should identify the caller by the address of its connection", func(t *testing.T) {
+		data, _ := json.Marshal(nightfury.Game{Name: "ludo", Instruction: "roll", Type: "web"})
+		request := httptest.NewRequest("POST", "/v1/games", bytes.NewReader(data))
+		request.RemoteAddr = "192.0.2.7:41000"
+		request.Header.Set("X-Forwarded-For", "10.0.0.1")
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		response := performRequest(router, "GET", "/v1/audit", nil)
+
+		var actual []nightfury.AuditEntry
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		if assert.Len(t, actual, 5) {
+			assert.Equal(t, "192.0.2.7", actual[4].Actor.ID)
+		}
+	})
+}
+
+func TestAuditOfClientsAndTeams(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	repository := db.DefaultRepository()
+	_ = nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.Ready}).Save(repository)
+	_ = nightfury.NewClient("kiosk-2", true, nightfury.GameStatus{Name: "ludo", Status: nightfury.Ready}).Save(repository)
+
+	_ = performRequest(router, "PUT", "/v1/clients/kiosk-1/profile", nightfury.Profile{Location: "hall b"})
+	_ = register(router, "kiosk-1", "application/json", `{"name": "Ada", "email": "ada@example.com", "privacy": true}`)
+	_ = performRequest(router, "POST", "/v1/teams", nightfury.NewTeam("red", "kiosk-1", "kiosk-2"))
+	_ = performRequest(router, "DELETE", "/v1/teams/red", nil)
+
+	response := performRequest(router, "GET", "/v1/audit", nil)
+
+	var actual []nightfury.AuditEntry
+	_ = json.Unmarshal(response.Body.Bytes(), &actual)
+	if assert.Len(t, actual, 4) {
+		assert.Equal(t, []string{"profile", "register", "create", "delete"},
+			[]string{actual[0].Action, actual[1].Action, actual[2].Action, actual[3].Action})
+		assert.Equal(t, []string{"client", "player", "team", "team"},
+			[]string{actual[0].Entity, actual[1].Entity, actual[2].Entity, actual[3].Entity})
+		assert.Equal(t, nightfury.APIActor, actual[0].Actor.Type)
+		assert.Contains(t, string(actual[0].After), `"location":"hall b"`)
+		assert.Equal(t, "kiosk-1", actual[1].Client)
+		assert.NotContains(t, string(actual[1].After), "ada@example.com")
+		assert.Nil(t, actual[2].Before)
+		assert.Nil(t, actual[3].After)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/boothgames/nightfury/api/socket"
 	"github.com/boothgames/nightfury/pkg/db"
 	"github.com/boothgames/nightfury/pkg/nightfury"
 	"github.com/gin-gonic/gin"
@@ -8,7 +9,7 @@ import (
 )
 
 func listClients(c *gin.Context) {
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	clients, err := nightfury.NewClientsFromRepo(repository)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -16,3 +17,136 @@ func listClients(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, clients)
 }
+
+func pauseClient(c *gin.Context) {
+	transitionClient(c, socket.PauseClient)
+}
+
+func resumeClient(c *gin.Context) {
+	transitionClient(c, socket.ResumeClient)
+}
+
+func transitionClient(c *gin.Context, transitionFn func(repo db.Repository, id string, actor nightfury.Actor) (nightfury.Game, error)) {
+	game, err := transitionFn(scopedRepository(c), c.Param("id"), apiActor(c))
+	if _, ok := err.(db.EntryNotFound); ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, game)
+}
+
+func assignGames(c *gin.Context) {
+	var names []string
+	repository := scopedRepository(c)
+	err := c.ShouldBindJSON(&names)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	games := make([]nightfury.Game, 0, len(names))
+	for _, name := range names {
+		game, err := nightfury.NewGameFromRepoWithName(repository, name)
+		if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": entryNotFoundErr.Error()})
+			return
+		} else if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		games = append(games, game)
+	}
+	client, err := socket.AssignGames(repository, c.Param("id"), games, apiActor(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+func readAssignedGames(c *gin.Context) {
+	value, _ := c.Get("client")
+	client := value.(nightfury.Client)
+	connected := socket.ConnectedGames(client.Name)
+	missing := client.Missing(connected...)
+	if missing == nil {
+		missing = nightfury.MissingGames{}
+	}
+	c.JSON(http.StatusOK, gin.H{"games": client.Games, "connected": connected, "missing": missing})
+}
+
+func populateClient(c *gin.Context) {
+	repository := scopedRepository(c)
+	client, err := nightfury.NewClientFromRepoWithName(repository, c.Param("id"))
+	if err != nil {
+		if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Set("client", client)
+}
+
+// clientDetails represents the client along with its computed status and current game
+type clientDetails struct {
+	nightfury.Client
+	Status      nightfury.Status `json:"status"`
+	CurrentGame string           `json:"currentGame,omitempty"`
+}
+
+func readClient(c *gin.Context) {
+	value, _ := c.Get("client")
+	client := value.(nightfury.Client)
+	currentGame, _ := client.CurrentGame()
+	c.JSON(http.StatusOK, clientDetails{Client: client, Status: client.Status(), CurrentGame: currentGame})
+}
+
+func deleteClient(c *gin.Context) {
+	value, _ := c.Get("client")
+	client, err := socket.DeleteClient(scopedRepository(c), value.(nightfury.Client).Name)
+	if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+		return
+	} else if inUseErr, ok := err.(socket.ClientInUse); ok {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": inUseErr.Error()})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "delete", "client", client.Name, client, nil)
+	c.Status(http.StatusOK)
+}
+
+func readClientHistory(c *gin.Context) {
+	value, _ := c.Get("client")
+	repository := scopedRepository(c)
+	history, err := nightfury.NewHistoryFromRepoWithName(repository, value.(nightfury.Client).Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+func editProfile(c *gin.Context) {
+	profile := nightfury.Profile{}
+	err := c.ShouldBindJSON(&profile)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	client, err := socket.EditProfile(scopedRepository(c), c.Param("id"), profile, apiActor(c))
+	if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
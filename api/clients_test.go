@@ -0,0 +1,214 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientPauseAndResume(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	repository := db.DefaultRepository()
+
+	client := nightfury.NewClient("kiosk-1", true)
+	client.GameStatuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.InProgress}
+	_ = client.Save(repository)
+
+	t.Run("should pause game in progress", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/clients/kiosk-1/pause", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		assert.Equal(t, nightfury.Paused, actual.GameStatuses["snakes"].Status)
+	})
+
+	t.Run("should fail to pause when no game is in progress", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/clients/kiosk-1/pause", nil)
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, `{"error":"no game in progress to pause"}`, response.Body.String())
+	})
+
+	t.Run("should resume paused game", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/clients/kiosk-1/resume", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		assert.Equal(t, nightfury.InProgress, actual.GameStatuses["snakes"].Status)
+	})
+
+	t.Run("should fail when client does not exist", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/clients/unknown/pause", nil)
+
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
+
+func TestClientAssignedGames(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+	_ = nightfury.Game{Name: "seeker", Instruction: "instruction", Type: "web"}.Save(repository)
+
+	t.Run("should assign games to client", func(t *testing.T) {
+		response := performRequest(router, "PUT", "/v1/clients/kiosk-1/games", []string{"snakes", "seeker"})
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		assert.Equal(t, []string{"snakes", "seeker"}, actual.Games)
+		assert.Len(t, actual.GameStatuses, 2)
+	})
+
+	t.Run("should report the missing games", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/clients/kiosk-1/games", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"games":["snakes","seeker"],"connected":[],"missing":["snakes","seeker"]}`, response.Body.String())
+	})
+
+	t.Run("should fail to assign games which do not exist", func(t *testing.T) {
+		response := performRequest(router, "PUT", "/v1/clients/kiosk-1/games", []string{"ludo"})
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, `{"error":"game with name ludo doesn't exists"}`, response.Body.String())
+	})
+
+	t.Run("should fail to assign games while a game is in progress", func(t *testing.T) {
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		client.GameStatuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.InProgress}
+		_ = client.Save(repository)
+
+		response := performRequest(router, "PUT", "/v1/clients/kiosk-1/games", []string{"seeker"})
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, `{"error":"cannot change games of client kiosk-1 while a game is in progress"}`, response.Body.String())
+	})
+}
+
+func TestClientProfile(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	repository := db.DefaultRepository()
+
+	client := nightfury.NewClient("kiosk-1", true)
+	client.Profile = nightfury.Profile{IPAddress: "10.0.0.12", UserAgent: "Mozilla/5.0", Version: "1.4.0"}
+	_ = client.Save(repository)
+
+	t.Run("should edit the admin editable fields", func(t *testing.T) {
+		profile := nightfury.Profile{Location: "hall b, stand 4", DeviceType: "tablet", Tags: []string{"vip"}, IPAddress: "127.0.0.1"}
+
+		response := performRequest(router, "PUT", "/v1/clients/kiosk-1/profile", profile)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should read the client along with its profile", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/clients/kiosk-1", nil)
+
+		actual := nightfury.Client{}
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, nightfury.Profile{
+			Location:   "hall b, stand 4",
+			DeviceType: "tablet",
+			Tags:       []string{"vip"},
+			IPAddress:  "10.0.0.12",
+			UserAgent:  "Mozilla/5.0",
+			Version:    "1.4.0",
+		}, actual.Profile)
+	})
+
+	t.Run("should fail when client does not exist", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/clients/unknown", nil)
+
+		assert.Equal(t, http.StatusNotFound, response.Code)
+		assert.Equal(t, `{"error":"client with name unknown doesn't exists"}`, response.Body.String())
+	})
+}
+
+func TestClientReadHistoryAndDelete(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	repository := db.DefaultRepository()
+
+	client := nightfury.NewClient("kiosk-1", true,
+		nightfury.GameStatus{Name: "seeker", Status: nightfury.Completed},
+		nightfury.GameStatus{Name: "snakes", Status: nightfury.InProgress},
+	)
+	_ = client.Save(repository)
+	_ = performRequest(router, "POST", "/v1/clients/kiosk-1/pause", nil)
+
+	t.Run("should read the client with its status and current game", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/clients/kiosk-1", nil)
+
+		actual := map[string]interface{}{}
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "kiosk-1", actual["name"])
+		assert.Equal(t, float64(nightfury.Paused), actual["status"])
+		assert.Equal(t, "snakes", actual["currentGame"])
+	})
+
+	t.Run("should read the history of the client", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/clients/kiosk-1/history", nil)
+
+		actual := nightfury.History{}
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusOK, response.Code)
+		if assert.Len(t, actual.Events, 3) {
+			assert.Equal(t, nightfury.GameAdded, actual.Events[0].Type)
+			paused := actual.Events[2]
+			assert.Equal(t, "snakes", paused.Game)
+			assert.Equal(t, nightfury.GamePaused, paused.Type)
+			assert.Equal(t, nightfury.Paused, paused.Status)
+		}
+	})
+
+	t.Run("should fail to delete a connected client", func(t *testing.T) {
+		response := performRequest(router, "DELETE", "/v1/clients/kiosk-1", nil)
+
+		assert.Equal(t, http.StatusConflict, response.Code)
+		assert.Equal(t, `{"error":"client kiosk-1 is connected"}`, response.Body.String())
+	})
+
+	t.Run("should fail to delete a member of a team", func(t *testing.T) {
+		disconnected, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		_ = disconnected.Disconnected().Save(repository)
+		team := nightfury.NewTeam("red", "kiosk-1")
+		_ = team.Form(repository)
+		defer func() { _ = team.Disband(repository) }()
+
+		response := performRequest(router, "DELETE", "/v1/clients/kiosk-1", nil)
+
+		assert.Equal(t, http.StatusConflict, response.Code)
+		assert.Equal(t, `{"error":"client kiosk-1 is a member of team red"}`, response.Body.String())
+	})
+
+	t.Run("should delete the client along with its history", func(t *testing.T) {
+		disconnected, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		_ = disconnected.Disconnected().Save(repository)
+
+		response := performRequest(router, "DELETE", "/v1/clients/kiosk-1", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		history, _ := nightfury.NewHistoryFromRepoWithName(repository, "kiosk-1")
+		assert.Empty(t, history.Events)
+		assert.Equal(t, http.StatusNotFound, performRequest(router, "GET", "/v1/clients/kiosk-1", nil).Code)
+	})
+
+	t.Run("should fail when client does not exist", func(t *testing.T) {
+		for _, response := range []*httptest.ResponseRecorder{
+			performRequest(router, "GET", "/v1/clients/unknown/history", nil),
+			performRequest(router, "DELETE", "/v1/clients/unknown", nil),
+		} {
+			assert.Equal(t, http.StatusNotFound, response.Code)
+			assert.Equal(t, `{"error":"client with name unknown doesn't exists"}`, response.Body.String())
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/api/socket"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+const (
+	eventContextKey      = "event"
+	repositoryContextKey = "repository"
+)
+
+// scopedRepository returns the repository of the event in the route, or the repository
+// of the active event for routes outside /v1/events/:event
+func scopedRepository(c *gin.Context) db.Repository {
+	if repository, ok := c.Get(repositoryContextKey); ok {
+		return repository.(db.Repository)
+	}
+	return db.DefaultRepository()
+}
+
+func listEvents(c *gin.Context) {
+	all, err := nightfury.NewEventsFromRepo(db.GlobalRepository())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, all)
+}
+
+func createEvent(c *gin.Context) {
+	event := nightfury.Event{}
+	repository := db.GlobalRepository()
+	err := c.ShouldBindJSON(&event)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, err = nightfury.NewEventFromRepoWithName(repository, event.ID())
+	if err == nil {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": fmt.Errorf("event %v already exists", event.ID()).Error()})
+		return
+	} else if _, ok := err.(db.EntryNotFound); !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	event.Active = false
+	event.CreatedAt = time.Now()
+	err = event.Save(repository)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, event)
+}
+
+func populateEvent(c *gin.Context) {
+	eventName := c.Param(eventContextKey)
+	event, err := nightfury.NewEventFromRepoWithName(db.GlobalRepository(), eventName)
+	if err != nil {
+		if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Set(eventContextKey, event)
+	c.Set(repositoryContextKey, db.NewScopedRepository(db.GlobalRepository(), event.ID()))
+}
+
+func readEvent(c *gin.Context) {
+	event, _ := c.Get(eventContextKey)
+	c.JSON(http.StatusOK, event)
+}
+
+func activateEvent(c *gin.Context) {
+	event, _ := c.Get(eventContextKey)
+	activated, err := socket.ActivateEvent(event.(nightfury.Event))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, activated)
+}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"strconv"
+	"time"
+)
+
+const keepAliveInterval = 15 * time.Second
+
+var defaultStream = NewStream(1000)
+
+// ConfigureBuffer sets how many recent events are kept for consumers resuming with Last-Event-ID,
+// none if size is negative
+func ConfigureBuffer(size int) {
+	if size < 0 {
+		size = 0
+	}
+	defaultStream = NewStream(size)
+}
+
+// Publish sends the message sent to a client to the server-sent event consumers
+func Publish(clientID, action string, data []byte) {
+	defaultStream.Publish(clientID, action, data)
+}
+
+// Close ends all the server-sent event streams
+func Close() {
+	defaultStream.Close()
+}
+
+// HandleEvents streams the messages sent to every client as server-sent events
+func HandleEvents(c *gin.Context) {
+	streamEvents(c, func(Event) bool {
+		return true
+	})
+}
+
+// HandleClientEvents streams the messages sent to a client as server-sent events
+func HandleClientEvents(c *gin.Context) {
+	clientID := c.Param("id")
+	streamEvents(c, func(event Event) bool {
+		return event.ClientID == clientID
+	})
+}
+
+func streamEvents(c *gin.Context, filterFn func(Event) bool) {
+	lastEventID, _ := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+	backlog, events, cancel := defaultStream.Subscribe(lastEventID)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	for _, event := range backlog {
+		if filterFn(event) {
+			writeEvent(c.Writer, event)
+		}
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			if filterFn(event) {
+				writeEvent(w, event)
+			}
+			return true
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func writeEvent(w io.Writer, event Event) {
+	_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Action, event.Data)
+}
@@ -0,0 +1,74 @@
+package events_test
+
+import (
+	"bufio"
+	"github.com/boothgames/nightfury/api/events"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestHandleClientEvents(t *testing.T) {
+	events.ConfigureBuffer(10)
+	router := gin.New()
+	router.GET("/v1/clients/:id/events", events.HandleClientEvents)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	events.Publish("kiosk-1", "started", []byte(`{"action":"started"}`))
+	events.Publish("kiosk-2", "started", []byte(`{"action":"started"}`))
+	events.Publish("kiosk-1", "completed", []byte(`{"action":"completed"}`))
+
+	request, _ := http.NewRequest("GET", server.URL+"/v1/clients/kiosk-1/events", nil)
+	request.Header.Set("Last-Event-ID", "1")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+
+	t.Run("should resume from the last event id of the client", func(t *testing.T) {
+		assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+		assert.Equal(t, []string{"id: 3", "event: completed", `data: {"action":"completed"}`}, readEvent(t, reader))
+	})
+
+	t.Run("should stream events of the client as they are published", func(t *testing.T) {
+		time.Sleep(50 * time.Millisecond)
+		events.Publish("kiosk-2", "failed", []byte(`{"action":"failed"}`))
+		events.Publish("kiosk-1", "failed", []byte(`{"action":"failed"}`))
+
+		assert.Equal(t, []string{"id: 5", "event: failed", `data: {"action":"failed"}`}, readEvent(t, reader))
+	})
+
+	events.Close()
+}
+
+func TestConfigureBuffer(t *testing.T) {
+	defer events.ConfigureBuffer(1000)
+
+	events.ConfigureBuffer(-1)
+
+	assert.NotPanics(t, func() {
+		events.Publish("kiosk-1", "started", []byte(`{"action":"started"}`))
+	})
+}
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"sync"
+)
+
+const subscriberBufferSize = 64
+
+// Event represents a message published to the server-sent event consumers
+type Event struct {
+	ID       uint64
+	ClientID string
+	Action   string
+	Data     []byte
+}
+
+// Stream keeps the recent events in a bounded buffer and fans them out to subscribers
+type Stream struct {
+	lock        sync.Mutex
+	nextID      uint64
+	size        int
+	buffer      []Event
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+// NewStream returns a stream which keeps the last size events for consumers to resume from
+func NewStream(size int) *Stream {
+	return &Stream{nextID: 1, size: size, subscribers: map[chan Event]struct{}{}}
+}
+
+// Publish appends the event to the buffer and sends it to every subscriber.
+// Subscribers which cannot keep up are dropped, they resume from the buffer on reconnect
+func (s *Stream) Publish(clientID, action string, data []byte) Event {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	event := Event{ID: s.nextID, ClientID: clientID, Action: action, Data: data}
+	s.nextID++
+	s.buffer = append(s.buffer, event)
+	if len(s.buffer) > s.size {
+		s.buffer = s.buffer[len(s.buffer)-s.size:]
+	}
+
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			delete(s.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+	return event
+}
+
+// Subscribe returns the buffered events published after lastEventID along with the channel of events
+// published from now on. The channel is closed by cancel, or when the stream is closed
+func (s *Stream) Subscribe(lastEventID uint64) ([]Event, <-chan Event, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var backlog []Event
+	for _, event := range s.buffer {
+		if event.ID > lastEventID {
+			backlog = append(backlog, event)
+		}
+	}
+
+	subscriber := make(chan Event, subscriberBufferSize)
+	if s.closed {
+		close(subscriber)
+		return backlog, subscriber, func() {}
+	}
+	s.subscribers[subscriber] = struct{}{}
+	return backlog, subscriber, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if _, ok := s.subscribers[subscriber]; ok {
+			delete(s.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Close ends every subscription, so that the long lived requests can finish
+func (s *Stream) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	for subscriber := range s.subscribers {
+		delete(s.subscribers, subscriber)
+		close(subscriber)
+	}
+}
@@ -0,0 +1,60 @@
+package events_test
+
+import (
+	"github.com/boothgames/nightfury/api/events"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStreamSubscribe(t *testing.T) {
+	t.Run("should return buffered events after the last event id", func(t *testing.T) {
+		stream := events.NewStream(10)
+		stream.Publish("kiosk-1", "started", []byte("one"))
+		stream.Publish("kiosk-1", "completed", []byte("two"))
+		stream.Publish("kiosk-2", "started", []byte("three"))
+
+		backlog, _, cancel := stream.Subscribe(1)
+		defer cancel()
+
+		assert.Equal(t, []events.Event{
+			{ID: 2, ClientID: "kiosk-1", Action: "completed", Data: []byte("two")},
+			{ID: 3, ClientID: "kiosk-2", Action: "started", Data: []byte("three")},
+		}, backlog)
+	})
+
+	t.Run("should keep only the most recent events", func(t *testing.T) {
+		stream := events.NewStream(2)
+		stream.Publish("kiosk-1", "started", []byte("one"))
+		stream.Publish("kiosk-1", "completed", []byte("two"))
+		stream.Publish("kiosk-1", "started", []byte("three"))
+
+		backlog, _, cancel := stream.Subscribe(0)
+		defer cancel()
+
+		if assert.Len(t, backlog, 2) {
+			assert.Equal(t, uint64(2), backlog[0].ID)
+			assert.Equal(t, uint64(3), backlog[1].ID)
+		}
+	})
+
+	t.Run("should send events published after subscribing", func(t *testing.T) {
+		stream := events.NewStream(10)
+		_, subscription, cancel := stream.Subscribe(0)
+		defer cancel()
+
+		published := stream.Publish("kiosk-1", "started", []byte("one"))
+
+		assert.Equal(t, published, <-subscription)
+	})
+
+	t.Run("should end subscriptions when closed", func(t *testing.T) {
+		stream := events.NewStream(10)
+		_, subscription, cancel := stream.Subscribe(0)
+		defer cancel()
+
+		stream.Close()
+
+		_, ok := <-subscription
+		assert.False(t, ok)
+	})
+}
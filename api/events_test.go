@@ -0,0 +1,110 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEventAPI(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+
+	snakes := nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}
+	ludo := nightfury.Game{Name: "ludo", Instruction: "instruction", Type: "web"}
+
+	t.Run("create event", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/conferences", nightfury.Event{Name: "devfest 2019", Title: "DevFest"})
+
+		assert.Equal(t, http.StatusCreated, response.Code)
+		_ = performRequest(router, "POST", "/v1/conferences", nightfury.Event{Name: "gophercon"})
+	})
+
+	t.Run("create event should fail when event already exists", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/conferences", nightfury.Event{Name: "devfest-2019"})
+
+		assert.Equal(t, http.StatusConflict, response.Code)
+		assert.Equal(t, `{"error":"event devfest-2019 already exists"}`, response.Body.String())
+	})
+
+	t.Run("list events", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/conferences", nil)
+
+		actual := map[string]nightfury.Event{}
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "DevFest", actual["devfest-2019"].Title)
+		assert.Len(t, actual, 2)
+	})
+
+	t.Run("keep games of events apart", func(t *testing.T) {
+		_ = performRequest(router, "POST", "/v1/events/devfest-2019/games", snakes)
+		_ = performRequest(router, "POST", "/v1/events/gophercon/games", ludo)
+
+		response := performRequest(router, "GET", "/v1/events/devfest-2019/games/ludo", nil)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+		response = performRequest(router, "GET", "/v1/events/gophercon/games/ludo", nil)
+		assert.Equal(t, http.StatusOK, response.Code)
+		response = performRequest(router, "GET", "/v1/games/ludo", nil)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("activate event", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/events/devfest-2019/activate", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "devfest-2019", db.ActiveScope())
+		response = performRequest(router, "GET", "/v1/games/snakes", nil)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("switch active event", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/events/gophercon/activate", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		active, ok, _ := nightfury.ActiveEvent(db.GlobalRepository())
+		assert.True(t, ok)
+		assert.Equal(t, "gophercon", active.Name)
+		response = performRequest(router, "GET", "/v1/games/snakes", nil)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("change clients of an event which is not active", func(t *testing.T) {
+		response := performRequest(router, "PUT", "/v1/events/devfest-2019/clients/kiosk-1/games", []string{"snakes"})
+		assert.Equal(t, http.StatusOK, response.Code)
+		response = performRequest(router, "GET", "/v1/clients/kiosk-1", nil)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+		response = performRequest(router, "GET", "/v1/events/devfest-2019/clients/kiosk-1", nil)
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		response = performRequest(router, "DELETE", "/v1/events/devfest-2019/clients/kiosk-1", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		response = performRequest(router, "GET", "/v1/events/devfest-2019/clients/kiosk-1", nil)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("erase a player of an event which is not active", func(t *testing.T) {
+		repository := db.NewScopedRepository(db.GlobalRepository(), "devfest-2019")
+		registration := nightfury.Registration{Name: "Ada", Email: "ada@example.com", Consent: nightfury.Consent{Privacy: true}}
+		player, _ := nightfury.NewPlayer(registration, "kiosk-2", time.Now())
+		_ = player.Save(repository)
+
+		response := performRequest(router, "DELETE", "/v1/events/devfest-2019/players/"+player.ID(), nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		_, err := nightfury.NewPlayerFromRepoWithID(repository, player.ID())
+		assert.IsType(t, db.EntryNotFound(""), err)
+	})
+
+	t.Run("read event should fail when event does not exist", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/events/unknown/games", nil)
+
+		assert.Equal(t, http.StatusNotFound, response.Code)
+		assert.Equal(t, `{"error":"event with name unknown doesn't exists"}`, response.Body.String())
+	})
+}
@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"encoding/json"
 	"fmt"
 	internalAssert "github.com/boothgames/nightfury/api/internal/assert"
 	"github.com/boothgames/nightfury/pkg/nightfury"
@@ -122,3 +123,51 @@ func TestGameUpdateFailure(t *testing.T) {
 		assert.Equal(t, expected, response.Body.String())
 	})
 }
+
+func TestGameUploadFlowValidation(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+
+	t.Run("upload games with prerequisites and branches", func(t *testing.T) {
+		games := []nightfury.Game{
+			{Name: "snakes", Instruction: "instruction", Type: "web", Branches: []string{"seeker", "smile"}},
+			{Name: "seeker", Instruction: "instruction", Type: "web"},
+			{Name: "smile", Instruction: "instruction", Type: "web"},
+		}
+
+		response := performRequest(router, "POST", "/v1/bulk/games", games)
+
+		assert.Equal(t, http.StatusCreated, response.Code)
+	})
+
+	t.Run("upload games should fail on missing references", func(t *testing.T) {
+		games := []nightfury.Game{
+			{Name: "final", Instruction: "instruction", Type: "web", Prerequisites: []string{"ludo"}},
+		}
+
+		response := performRequest(router, "POST", "/v1/bulk/games", games)
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, `{"error":"game final refers to missing game ludo"}`, response.Body.String())
+	})
+
+	t.Run("upload games should fail on cycles with existing games", func(t *testing.T) {
+		games := []nightfury.Game{
+			{Name: "seeker", Instruction: "instruction", Type: "web", Branches: []string{"snakes"}},
+		}
+
+		response := performRequest(router, "POST", "/v1/bulk/games", games)
+
+		actual := map[string]string{}
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, "games form a cycle seeker -> snakes -> seeker", actual["error"])
+	})
+
+	t.Run("delete game should fail when other games refer to it", func(t *testing.T) {
+		response := performRequest(router, "DELETE", "/v1/games/seeker", nil)
+
+		assert.Equal(t, http.StatusConflict, response.Code)
+		assert.Equal(t, `{"error":"game snakes refers to missing game seeker"}`, response.Body.String())
+	})
+}
@@ -9,7 +9,7 @@ import (
 )
 
 func listGames(c *gin.Context) {
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	games, err := nightfury.NewGamesFromRepo(repository)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -20,42 +20,56 @@ func listGames(c *gin.Context) {
 
 func createGame(c *gin.Context) {
 	game := nightfury.Game{}
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	err := c.ShouldBindJSON(&game)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	err = nightfury.ValidateGames(repository, game)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	before := existing(existingGame, repository, game.Name)
 	err = game.Save(repository)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	audit(c, "create", "game", game.ID(), before, game)
 	c.JSON(http.StatusCreated, game)
 }
 
 func uploadGames(c *gin.Context) {
 	var games []nightfury.Game
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	err := c.ShouldBindJSON(&games)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	err = nightfury.ValidateGames(repository, games...)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	for _, game := range games {
+		before := existing(existingGame, repository, game.Name)
 		err = game.Save(repository)
 
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		audit(c, "upload", "game", game.ID(), before, game)
 	}
 	c.JSON(http.StatusCreated, games)
 }
 
 func populateGame(c *gin.Context) {
 	gameName := c.Param("id")
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	game, err := nightfury.NewGameFromRepoWithName(repository, gameName)
 	if err != nil {
 		if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
@@ -87,23 +101,38 @@ func updateGame(c *gin.Context) {
 		return
 	}
 
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
+	err = nightfury.ValidateGames(repository, gameToBeUpdated)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	err = gameToBeUpdated.Save(repository)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	audit(c, "update", "game", gameToBeUpdated.ID(), currentGame, gameToBeUpdated)
 	c.JSON(http.StatusOK, gameToBeUpdated)
 }
 
 func deleteGame(c *gin.Context) {
 	game, _ := c.Get("game")
 	gameToBeDeleted := game.(nightfury.Game)
-	repository := db.DefaultRepository()
-	err := gameToBeDeleted.Delete(repository)
+	repository := scopedRepository(c)
+	err := nightfury.ValidateDeletion(repository, gameToBeDeleted)
+	if invalidFlowErr, ok := err.(nightfury.InvalidFlow); ok {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": invalidFlowErr.Error()})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	err = gameToBeDeleted.Delete(repository)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	audit(c, "delete", "game", gameToBeDeleted.ID(), gameToBeDeleted, nil)
 	c.Status(http.StatusOK)
 }
@@ -10,7 +10,7 @@ import (
 const hintContextKey = "hint"
 
 func listHints(c *gin.Context) {
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	hints, err := nightfury.NewHintsFromRepo(repository)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -21,41 +21,45 @@ func listHints(c *gin.Context) {
 
 func createHint(c *gin.Context) {
 	hint := nightfury.Hint{}
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	err := c.ShouldBindJSON(&hint)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	before := existing(existingHint, repository, hint.Title)
 	err = hint.Save(repository)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	audit(c, "create", "hint", hint.ID(), before, hint)
 	c.JSON(http.StatusCreated, hint)
 }
 
 func uploadHints(c *gin.Context) {
 	var hints []nightfury.Hint
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	err := c.ShouldBindJSON(&hints)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	for _, hint := range hints {
+		before := existing(existingHint, repository, hint.Title)
 		err = hint.Save(repository)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		audit(c, "upload", "hint", hint.ID(), before, hint)
 	}
 	c.JSON(http.StatusCreated, hints)
 }
 
 func populateHint(c *gin.Context) {
 	hintTitle := c.Param("id")
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	hint, err := nightfury.NewHintFromRepoWithName(repository, hintTitle)
 	if err != nil {
 		if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
@@ -88,23 +92,25 @@ func updateHint(c *gin.Context) {
 		return
 	}
 
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	err = hintToBeUpdated.Save(repository)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	audit(c, "update", "hint", hintToBeUpdated.ID(), currentHint, hintToBeUpdated)
 	c.JSON(http.StatusOK, hintToBeUpdated)
 }
 
 func deleteHint(c *gin.Context) {
 	hint, _ := c.Get(hintContextKey)
 	hintToBeDeleted := hint.(nightfury.Hint)
-	repository := db.DefaultRepository()
+	repository := scopedRepository(c)
 	err := hintToBeDeleted.Delete(repository)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	audit(c, "delete", "hint", hintToBeDeleted.ID(), hintToBeDeleted, nil)
 	c.Status(http.StatusOK)
 }
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/boothgames/nightfury/log"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// assignRequestID tags the request with the id sent by the caller, or with a random one
+func assignRequestID(c *gin.Context) {
+	id := c.GetHeader(requestIDHeader)
+	if id == "" {
+		id = newRequestID()
+	}
+	c.Set(log.RequestIDField, id)
+	c.Header(requestIDHeader, id)
+}
+
+func newRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// logAccess logs the request once handled, along with its request id and the client and game it concerns.
+// Socket requests are logged when the socket closes
+func logAccess(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+	status := c.Writer.Status()
+	path := route(c, status)
+	fields := log.Fields{
+		log.RequestIDField: c.GetString(log.RequestIDField),
+		"method":           c.Request.Method,
+		"path":             c.Request.URL.Path,
+		"route":            path,
+		"status":           status,
+		"latency":          time.Since(start).String(),
+		"ip":               c.ClientIP(),
+	}
+	if strings.Contains(path, "/clients/:id") {
+		fields[log.ClientField] = c.Param("id")
+	}
+	if strings.Contains(path, "/games/:name") {
+		fields[log.GameField] = c.Param("name")
+	}
+	logger := log.WithFields(fields)
+	switch {
+	case status >= http.StatusInternalServerError:
+		logger.Errorf("%v %v %v", c.Request.Method, c.Request.URL.Path, status)
+	case status >= http.StatusBadRequest:
+		logger.Warnf("%v %v %v", c.Request.Method, c.Request.URL.Path, status)
+	default:
+		logger.Infof("%v %v %v", c.Request.Method, c.Request.URL.Path, status)
+	}
+}
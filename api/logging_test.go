@@ -0,0 +1,53 @@
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/boothgames/nightfury/log"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	out := &bytes.Buffer{}
+	restore := log.SetOutput(out)
+	defer restore()
+	_ = log.SetLogFormat("json")
+	defer func() { _ = log.SetLogFormat("text") }()
+
+	t.Run("should echo the request id sent by the caller", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/v1/clients/kiosk-1", nil)
+		request.Header.Set("X-Request-ID", "req-42")
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assert.Equal(t, "req-42", response.Header().Get("X-Request-ID"))
+		entry := lastLogEntry(out)
+		assert.Equal(t, "req-42", entry["request_id"])
+		assert.Equal(t, "kiosk-1", entry["client"])
+		assert.Equal(t, "/v1/clients/:id", entry["route"])
+		assert.Equal(t, float64(http.StatusNotFound), entry["status"])
+	})
+
+	t.Run("should assign a request id when the caller does not send one", func(t *testing.T) {
+		response := performRequest(router, "GET", "/ping", nil)
+
+		assert.Len(t, response.Header().Get("X-Request-ID"), 16)
+		assert.Equal(t, response.Header().Get("X-Request-ID"), lastLogEntry(out)["request_id"])
+	})
+}
+
+func lastLogEntry(out *bytes.Buffer) map[string]interface{} {
+	entry := map[string]interface{}{}
+	scanner := bufio.NewScanner(bytes.NewReader(out.Bytes()))
+	for scanner.Scan() {
+		_ = json.Unmarshal(scanner.Bytes(), &entry)
+	}
+	return entry
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"github.com/boothgames/nightfury/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const unmatchedRoute = "unmatched"
+
+// recordRequestDuration observes the latency of the http requests by route, the socket connections are left out
+func recordRequestDuration(c *gin.Context) {
+	if c.IsWebsocket() {
+		return
+	}
+	start := time.Now()
+	c.Next()
+	status := c.Writer.Status()
+	metrics.RequestDuration.
+		WithLabelValues(c.Request.Method, route(c, status), strconv.Itoa(status)).
+		Observe(time.Since(start).Seconds())
+}
+
+// route returns the path of the request with the values of its params replaced by their names,
+// i.e. /v1/clients/kiosk-1 is reported as /v1/clients/:id
+func route(c *gin.Context, status int) string {
+	if status == http.StatusNotFound && len(c.Params) == 0 {
+		return unmatchedRoute
+	}
+	segments := strings.Split(c.Request.URL.Path, "/")
+	next := 0
+	for _, param := range c.Params {
+		for i := next; i < len(segments); i++ {
+			if segments[i] == param.Value {
+				segments[i] = ":" + param.Key
+				next = i + 1
+				break
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func exposeMetrics(c *gin.Context) {
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
+}
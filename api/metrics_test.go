@@ -0,0 +1,33 @@
+package api_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+
+	_ = performRequest(router, "GET", "/v1/clients/kiosk-1", nil)
+	_ = performRequest(router, "GET", "/v1/unknown", nil)
+
+	response := performRequest(router, "GET", "/metrics", nil)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	t.Run("should expose the request latency by route", func(t *testing.T) {
+		assert.Contains(t, response.Body.String(), `nightfury_http_request_duration_seconds_count{method="GET",route="/v1/clients/:id",status="404"}`)
+		assert.Contains(t, response.Body.String(), `nightfury_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"}`)
+	})
+
+	t.Run("should expose the socket sessions by engine", func(t *testing.T) {
+		assert.Contains(t, response.Body.String(), `nightfury_socket_sessions{engine="clients"} 0`)
+		assert.Contains(t, response.Body.String(), `nightfury_socket_sessions{engine="games"} 0`)
+	})
+
+	t.Run("should expose the bolt transaction stats", func(t *testing.T) {
+		assert.Contains(t, response.Body.String(), "nightfury_bolt_read_tx_total")
+		assert.Contains(t, response.Body.String(), "nightfury_bolt_writes_total")
+	})
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"github.com/boothgames/nightfury/api/socket"
+	"github.com/boothgames/nightfury/log"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// registrationForm represents the registration submitted as a form or as json
+type registrationForm struct {
+	Name      string `json:"name" form:"name"`
+	Email     string `json:"email" form:"email"`
+	Privacy   bool   `json:"privacy" form:"privacy"`
+	Marketing bool   `json:"marketing" form:"marketing"`
+}
+
+func registerPlayer(c *gin.Context) {
+	form := registrationForm{}
+	err := c.ShouldBind(&form)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	registration := nightfury.Registration{
+		Name:    form.Name,
+		Email:   form.Email,
+		Consent: nightfury.Consent{Privacy: form.Privacy, Marketing: form.Marketing},
+	}
+	player, err := socket.RegisterPlayer(scopedRepository(c), c.Param("id"), registration, apiActor(c))
+	if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, player)
+}
+
+// listPlayers lists the players without their name and email, only those registered with the email if given
+func listPlayers(c *gin.Context) {
+	players, err := nightfury.NewPlayersFromRepo(scopedRepository(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	email := c.Query("email")
+	redacted := []nightfury.Player{}
+	for _, player := range players {
+		if email == "" || player.Email == email {
+			redacted = append(redacted, player.Redacted())
+		}
+	}
+	c.JSON(http.StatusOK, redacted)
+}
+
+// exportPlayers writes the players who agreed to marketing as csv
+func exportPlayers(c *gin.Context) {
+	players, err := nightfury.NewPlayersFromRepo(scopedRepository(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="players.csv"`)
+	c.Status(http.StatusOK)
+	if err := nightfury.ExportPlayers(c.Writer, players); err != nil {
+		log.Error(err)
+	}
+}
+
+// erasePlayer deletes the player, the audit log keeping only the id of the erased player
+func erasePlayer(c *gin.Context) {
+	player, err := nightfury.NewPlayerFromRepoWithID(scopedRepository(c), c.Param("id"))
+	if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := socket.ErasePlayer(scopedRepository(c), player); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "erase", "player", player.ID(), nil, nil)
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,101 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPlayers(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	repository := db.DefaultRepository()
+	_ = nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes"}).Save(repository)
+	_ = nightfury.NewClient("kiosk-2", true, nightfury.GameStatus{Name: "snakes"}).Save(repository)
+	var registered nightfury.Player
+
+	t.Run("should register the player submitting the form", func(t *testing.T) {
+		form := url.Values{"name": {"Ada"}, "email": {"ada@example.com"}, "privacy": {"true"}, "marketing": {"true"}}
+
+		response := register(router, "kiosk-1", "application/x-www-form-urlencoded", form.Encode())
+
+		_ = json.Unmarshal(response.Body.Bytes(), &registered)
+		assert.Equal(t, http.StatusCreated, response.Code)
+		assert.Equal(t, "Ada", registered.Name)
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		assert.Equal(t, registered.ID(), client.Player)
+	})
+
+	t.Run("should register the player posting json", func(t *testing.T) {
+		response := register(router, "kiosk-2", "application/json", `{"name": "Alan", "email": "alan@example.com", "privacy": true}`)
+
+		assert.Equal(t, http.StatusCreated, response.Code)
+	})
+
+	t.Run("should refuse a registration without consent", func(t *testing.T) {
+		response := register(router, "kiosk-2", "application/json", `{"name": "Alan", "email": "alan@example.com"}`)
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, `{"error":"consent to the privacy policy is required to register"}`, response.Body.String())
+	})
+
+	t.Run("should fail for an unknown client", func(t *testing.T) {
+		response := register(router, "unknown", "application/json", `{"name": "Alan", "email": "alan@example.com", "privacy": true}`)
+
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should find the players by email", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/players?email=ada@example.com", nil)
+
+		var actual []nightfury.Player
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, []nightfury.Player{registered.Redacted()}, actual)
+	})
+
+	t.Run("should list the players without their name and email", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/players", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), registered.ID())
+		assert.NotContains(t, response.Body.String(), "Ada")
+		assert.NotContains(t, response.Body.String(), "alan@example.com")
+	})
+
+	t.Run("should export only the players who agreed to marketing", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/players/export", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "text/csv", response.Header().Get("Content-Type"))
+		assert.Contains(t, response.Body.String(), "Ada,ada@example.com,")
+		assert.NotContains(t, response.Body.String(), "alan@example.com")
+		assert.NotContains(t, response.Body.String(), "kiosk-1")
+	})
+
+	t.Run("should erase the player", func(t *testing.T) {
+		response := performRequest(router, "DELETE", "/v1/players/"+registered.ID(), nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		_, err := nightfury.NewPlayerFromRepoWithID(repository, registered.ID())
+		assert.IsType(t, db.EntryNotFound(""), err)
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		assert.Empty(t, client.Player)
+		response = performRequest(router, "DELETE", "/v1/players/"+registered.ID(), nil)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
+
+func register(router http.Handler, client, contentType, body string) *httptest.ResponseRecorder {
+	request, _ := http.NewRequest("POST", "/v1/clients/"+client+"/register", strings.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	return response
+}
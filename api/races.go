@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/api/socket"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func listRaces(c *gin.Context) {
+	repository := scopedRepository(c)
+	races, err := nightfury.NewRacesFromRepo(repository)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, races)
+}
+
+func createRace(c *gin.Context) {
+	request := nightfury.Race{}
+	repository := scopedRepository(c)
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, err = nightfury.NewRaceFromRepoWithName(repository, request.Name)
+	if err == nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Errorf("race %v already exists", request.Name).Error()})
+		return
+	} else if _, ok := err.(db.EntryNotFound); !ok {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, name := range request.Games {
+		_, err := nightfury.NewGameFromRepoWithName(repository, name)
+		if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": entryNotFoundErr.Error()})
+			return
+		} else if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	race := nightfury.NewRace(request.Name, request.Clients, request.Games)
+	err = race.Save(repository)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, race)
+}
+
+func populateRace(c *gin.Context) {
+	raceName := c.Param("id")
+	repository := scopedRepository(c)
+	race, err := nightfury.NewRaceFromRepoWithName(repository, raceName)
+	if err != nil {
+		if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Set("race", race)
+}
+
+func readRace(c *gin.Context) {
+	race, _ := c.Get("race")
+	c.JSON(http.StatusOK, race)
+}
+
+func startRace(c *gin.Context) {
+	race, err := socket.StartRace(scopedRepository(c), c.Param("id"), apiActor(c))
+	if _, ok := err.(db.EntryNotFound); ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, race)
+}
@@ -0,0 +1,60 @@
+package api_test
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestRaceAPI(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+
+	t.Run("create race", func(t *testing.T) {
+		race := nightfury.NewRace("final", []string{"kiosk-1", "kiosk-2"}, []string{"snakes"})
+
+		response := performRequest(router, "POST", "/v1/races", race)
+
+		assert.Equal(t, http.StatusCreated, response.Code)
+	})
+
+	t.Run("create race should fail when game does not exist", func(t *testing.T) {
+		race := nightfury.NewRace("semi-final", []string{"kiosk-1", "kiosk-2"}, []string{"ludo"})
+
+		response := performRequest(router, "POST", "/v1/races", race)
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, `{"error":"game with name ludo doesn't exists"}`, response.Body.String())
+	})
+
+	t.Run("create race should fail with less than two clients", func(t *testing.T) {
+		race := nightfury.NewRace("semi-final", []string{"kiosk-1"}, []string{"snakes"})
+
+		response := performRequest(router, "POST", "/v1/races", race)
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("read race", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/races/final", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("start race should fail when clients are not connected", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/races/final/start", nil)
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, `{"error":"client kiosk-1 is not connected"}`, response.Body.String())
+	})
+
+	t.Run("start race should fail when race does not exist", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/races/semi-final/start", nil)
+
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
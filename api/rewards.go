@@ -0,0 +1,145 @@
+package api
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// redemption represents the code of the reward a staff member hands out, and optionally who they are
+type redemption struct {
+	Code string `json:"code" binding:"required"`
+	By   string `json:"by"`
+}
+
+func listPrizes(c *gin.Context) {
+	prizes, err := nightfury.NewPrizesFromRepo(scopedRepository(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prizes)
+}
+
+func createPrize(c *gin.Context) {
+	prize := nightfury.Prize{}
+	repository := scopedRepository(c)
+	err := c.ShouldBindJSON(&prize)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = prize.Validate()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	before := existing(existingPrize, repository, prize.Name)
+	err = prize.Save(repository)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "create", "prize", prize.ID(), before, prize)
+	c.JSON(http.StatusCreated, prize)
+}
+
+func populatePrize(c *gin.Context) {
+	prize, err := nightfury.NewPrizeFromRepoWithName(scopedRepository(c), c.Param("id"))
+	if err != nil {
+		if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Set("prize", prize)
+}
+
+func readPrize(c *gin.Context) {
+	prize, _ := c.Get("prize")
+	c.JSON(http.StatusOK, prize)
+}
+
+func updatePrize(c *gin.Context) {
+	prize, _ := c.Get("prize")
+	currentPrize := prize.(nightfury.Prize)
+	prizeToBeUpdated := nightfury.Prize{}
+	err := c.ShouldBindJSON(&prizeToBeUpdated)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if currentPrize.Name != prizeToBeUpdated.Name {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Errorf("name cannot be different").Error()})
+		return
+	}
+	err = prizeToBeUpdated.Validate()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = prizeToBeUpdated.Save(scopedRepository(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "update", "prize", prizeToBeUpdated.ID(), currentPrize, prizeToBeUpdated)
+	c.JSON(http.StatusOK, prizeToBeUpdated)
+}
+
+func deletePrize(c *gin.Context) {
+	prize, _ := c.Get("prize")
+	prizeToBeDeleted := prize.(nightfury.Prize)
+	err := prizeToBeDeleted.Delete(scopedRepository(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "delete", "prize", prizeToBeDeleted.ID(), prizeToBeDeleted, nil)
+	c.Status(http.StatusOK)
+}
+
+func existingPrize(repo db.Repository, name string) (interface{}, error) {
+	return nightfury.NewPrizeFromRepoWithName(repo, name)
+}
+
+func listRewards(c *gin.Context) {
+	rewards, err := nightfury.NewRewardsFromRepo(scopedRepository(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rewards)
+}
+
+// redeemReward marks the reward as handed out by the staff member, identified by the address of the caller
+// unless they give their name
+func redeemReward(c *gin.Context) {
+	request := redemption{}
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.By == "" {
+		request.By = apiActor(c).ID
+	}
+	reward, err := nightfury.RedeemReward(scopedRepository(c), request.Code, request.By, time.Now())
+	if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+		return
+	} else if redeemedErr, ok := err.(nightfury.RewardRedeemed); ok {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": redeemedErr.Error()})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "redeem", "reward", reward.Code, nil, reward)
+	c.JSON(http.StatusOK, reward)
+}
@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPrizes(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	prize := nightfury.Prize{Name: "hoodie", Title: "Conference hoodie", Quantity: 20, Rules: nightfury.Eligibility{Within: "5m"}}
+
+	t.Run("should create the prize", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/prizes", prize)
+
+		assert.Equal(t, http.StatusCreated, response.Code)
+		actual, _ := nightfury.NewPrizeFromRepoWithName(db.DefaultRepository(), "hoodie")
+		assert.Equal(t, prize, actual)
+	})
+
+	t.Run("should refuse invalid rules", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/prizes", nightfury.Prize{Name: "mug", Rules: nightfury.Eligibility{Within: "soon"}})
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("should restock the prize", func(t *testing.T) {
+		restocked := prize
+		restocked.Quantity = 50
+
+		response := performRequest(router, "PUT", "/v1/prizes/hoodie", restocked)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		actual, _ := nightfury.NewPrizeFromRepoWithName(db.DefaultRepository(), "hoodie")
+		assert.Equal(t, 50, actual.Quantity)
+	})
+
+	t.Run("should delete the prize", func(t *testing.T) {
+		response := performRequest(router, "DELETE", "/v1/prizes/hoodie", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		response = performRequest(router, "GET", "/v1/prizes/hoodie", nil)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
+
+func TestRedeemReward(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	repository := db.DefaultRepository()
+	_ = nightfury.Prize{Name: "hoodie", Quantity: 1}.Save(repository)
+	reward, _, _ := nightfury.AllocateReward(repository, nightfury.NewClient("kiosk-1", true), nightfury.Run{}, time.Now())
+
+	t.Run("should redeem the reward", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/rewards/redeem", map[string]string{"code": reward.Code, "by": "alice"})
+
+		actual := nightfury.Reward{}
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "hoodie", actual.Prize)
+		assert.Equal(t, "alice", actual.RedeemedBy)
+		assert.NotNil(t, actual.RedeemedAt)
+	})
+
+	t.Run("should refuse to redeem the reward twice", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/rewards/redeem", map[string]string{"code": reward.Code})
+
+		assert.Equal(t, http.StatusConflict, response.Code)
+	})
+
+	t.Run("should fail for an unknown code", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/rewards/redeem", map[string]string{"code": "UNKNOWN"})
+
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should fail without a code", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/rewards/redeem", map[string]string{})
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("should list the rewards", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/rewards", nil)
+
+		actual := map[string]nightfury.Reward{}
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, actual, reward.Code)
+	})
+}
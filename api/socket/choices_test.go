@@ -0,0 +1,62 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBranchChoices(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web", Branches: []string{"seeker", "smile"}}.Save(repository)
+	_ = nightfury.Game{Name: "seeker", Instruction: "instruction", Type: "web"}.Save(repository)
+	_ = nightfury.Game{Name: "smile", Instruction: "instruction", Type: "web"}.Save(repository)
+
+	clientConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer clientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+	snakesConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+	defer snakesConn.Close()
+	seekerConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/seeker")
+	defer seekerConn.Close()
+	smileConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/smile")
+	defer smileConn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	t.Run("should start the game branching into the others", func(t *testing.T) {
+		message, _ := NewMessage(startClient, nil)
+		_ = clientConn.WriteJSON(message)
+
+		assert.Equal(t, startClient, readMessage(t, snakesConn).Action)
+		assert.Equal(t, messageAck, readMessage(t, clientConn).Action)
+	})
+
+	t.Run("should offer the branches once the game is completed", func(t *testing.T) {
+		message, _ := NewMessage(gameCompleted, nil)
+		_ = snakesConn.WriteJSON(message)
+
+		assert.Equal(t, gameCompleted, readMessage(t, clientConn).Action)
+		actual := readMessage(t, clientConn)
+		choices := Choices{}
+		_ = actual.DecodePayload(&choices)
+		assert.Equal(t, offerChoice, actual.Action)
+		if assert.Len(t, choices.Games, 2) {
+			assert.Equal(t, "seeker", choices.Games[0].Name)
+			assert.Equal(t, "smile", choices.Games[1].Name)
+		}
+	})
+
+	t.Run("should start the chosen branch", func(t *testing.T) {
+		message, _ := NewMessage(chooseClient, Choice{Game: "smile"})
+		_ = clientConn.WriteJSON(message)
+
+		assert.Equal(t, startClient, readMessage(t, smileConn).Action)
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		assert.Equal(t, nightfury.InProgress, client.GameStatuses["smile"].Status)
+		assert.Equal(t, nightfury.Skipped, client.GameStatuses["seeker"].Status)
+	})
+}
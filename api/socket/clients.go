@@ -1,7 +1,6 @@
 package socket
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/boothgames/nightfury/log"
 	"github.com/boothgames/nightfury/pkg/db"
@@ -9,18 +8,98 @@ import (
 	"github.com/gin-gonic/gin"
 	"gopkg.in/olahol/melody.v1"
 	"net/http"
+	"strings"
+	"time"
 )
 
 const (
-	startClient = "start"
-	resetClient = "reset"
+	startClient  = "start"
+	resetClient  = "reset"
+	chooseClient = "choose"
+	offerChoice  = "choices"
+	editProfile  = "profile"
 )
 
-// HandleClients handle socket connection related to clients
+// EditProfile updates the admin editable fields of the client profile and audits the change
+func EditProfile(repo db.Repository, id string, profile nightfury.Profile, actor nightfury.Actor) (nightfury.Client, error) {
+	client, err := lockedClient(repo, id)
+	if err != nil {
+		return client, err
+	}
+	defer release()
+	before := client.Profile
+	client.Profile = client.Profile.Edit(profile)
+	if err := client.Save(repo); err != nil {
+		return client, err
+	}
+	auditChange(repo, actor, editProfile, "client", client.Name, client.Name, before, client.Profile)
+	return client, nil
+}
+
+// ClientInUse represents the error of deleting a client which is connected or a member of a team
+type ClientInUse string
+
+func (c ClientInUse) Error() string {
+	return string(c)
+}
+
+// DeleteClient deletes the client along with its history, returns error if the client or any of its
+// games is connected, or if the client is a member of a team
+func DeleteClient(repo db.Repository, id string) (nightfury.Client, error) {
+	client, err := lockedClient(repo, id)
+	if err != nil {
+		return client, err
+	}
+	defer release()
+	if client.Available {
+		return client, ClientInUse(fmt.Sprintf("client %v is connected", client.Name))
+	}
+	if connected := ConnectedGames(client.Name); len(connected) > 0 {
+		return client, ClientInUse(fmt.Sprintf("games %v of client %v are connected", strings.Join(connected, ", "), client.Name))
+	}
+	if client.Team != "" {
+		return client, ClientInUse(fmt.Sprintf("client %v is a member of team %v", client.Name, client.Team))
+	}
+	for name := range client.GameStatuses {
+		cancelRemoval(client, nightfury.Game{Name: name})
+	}
+	history, err := nightfury.NewHistoryFromRepoWithName(repo, client.Name)
+	if err != nil {
+		return client, err
+	}
+	if err := history.Delete(repo); err != nil {
+		return client, err
+	}
+	clientLogger(client).Infof("client %v deleted", client.Name)
+	return client, client.Delete(repo)
+}
+
+// Choice represents the branch chosen by the client
+type Choice struct {
+	Game string `json:"game"`
+}
+
+// Choices represents the branches offered to the client once their branching game is completed
+type Choices struct {
+	Games []nightfury.Game `json:"games"`
+}
+
+// HandleClients handle socket connection related to clients, the device details are captured
+// from the request along with the device and version query parameters
 func HandleClients(c *gin.Context) {
 	id := c.Param("id")
+	if !authorized(c, id, "") {
+		return
+	}
 	err := clientEngine.HandleRequestWithKeys(c.Writer, c.Request, map[string]interface{}{
 		socketClientID: id,
+		socketRequest:  c.GetString(log.RequestIDField),
+		socketDevice: nightfury.Device{
+			Type:      c.Query("device"),
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Version:   c.Query("version"),
+		},
 	})
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
@@ -28,10 +107,10 @@ func HandleClients(c *gin.Context) {
 }
 
 func clientConnected(session *melody.Session) {
-	lock.Lock()
-	defer func() {
-		lock.Unlock()
-	}()
+	if !acquire() {
+		return
+	}
+	defer release()
 	client, repository, err := clientFromSession(session, func(id string) (nightfury.Client, error) {
 		return nightfury.NewClient(id, true), nil
 	})
@@ -39,17 +118,19 @@ func clientConnected(session *melody.Session) {
 		logErr(err)
 		return
 	}
-	connectedClient := client.Connected()
+	now := time.Now()
+	device, _ := session.Keys[socketDevice].(nightfury.Device)
+	connectedClient := client.Connected().Seen(now).ConnectedFrom(device, now)
 	err = connectedClient.Save(repository)
 	logErr(err)
-	log.Infof("client %v connected", client.Name)
+	sessionLogger(session).Infof("client %v connected", client.Name)
 }
 
 func clientDisconnected(session *melody.Session) {
-	lock.Lock()
-	defer func() {
-		lock.Unlock()
-	}()
+	if !acquire() {
+		return
+	}
+	defer release()
 	client, repository, err := clientFromSession(session, func(id string) (nightfury.Client, error) {
 		return nightfury.NewClient(id, false), nil
 	})
@@ -60,57 +141,115 @@ func clientDisconnected(session *melody.Session) {
 	connectedClient := client.Disconnected()
 	err = connectedClient.Save(repository)
 	logErr(err)
-	log.Infof("client %v disconnected", client.Name)
+	sessionLogger(session).Infof("client %v disconnected", client.Name)
 }
 
 func clientMessageReceived(session *melody.Session, data []byte) {
-	lock.Lock()
-	defer func() {
-		lock.Unlock()
-	}()
-	client, _, err := clientFromSession(session, func(id string) (client nightfury.Client, e error) {
-		return nightfury.Client{}, fmt.Errorf("client %v not found", client.Name)
-	})
+	if !acquire() {
+		return
+	}
+	defer release()
+	clientMessage, err := ParseMessage(data)
 	if err != nil {
-		logErr(err)
+		reply(session, clientMessage, err)
+		return
 	}
 
-	clientMessage := Message{}
-	err = json.Unmarshal(data, &clientMessage)
+	client, repository, err := clientFromSession(session, func(id string) (client nightfury.Client, e error) {
+		return nightfury.Client{}, fmt.Errorf("client %v not found", id)
+	})
 	if err != nil {
-		log.Error(err)
+		reply(session, clientMessage, err)
+		return
+	}
+	seenClient := client.Seen(time.Now())
+	if err := seenClient.Save(repository); err != nil {
+		reply(session, clientMessage, err)
 		return
 	}
 
-	processClientMessage(clientMessage, *client)
+	sessionLogger(session).WithFields(log.Fields{log.ActionField: clientMessage.Action}).
+		Debugf("client '%v' sent %v", seenClient.Name, clientMessage.Action)
+	before := seenClient.GameStatuses.Copy()
+	actor := sessionActor(session)
+	err = processClientMessage(repository, clientMessage, seenClient, actor)
+	recordChanges(repository, seenClient, before, actor, clientMessage.Action)
+	reply(session, clientMessage, err)
 }
 
-func processClientMessage(message Message, client nightfury.Client) {
+func processClientMessage(repository db.Repository, message Message, client nightfury.Client, actor nightfury.Actor) error {
+	logger := clientLogger(client).WithFields(log.Fields{log.ActionField: message.Action})
 	switch message.Action {
 	case startClient:
-		log.Infof("client '%v' has requested to start playing", client.Name)
-		firstGame, err := client.Start()
-		if err != nil {
-			log.Errorf("cannot start games of client %v. Error: %v", client.Name, err)
-			return
+		logger.Infof("client '%v' has requested to start playing", client.Name)
+		firstGame, err := client.Start(repository, connectedGamesOf(client)...)
+		if missing, ok := err.(nightfury.MissingGames); ok {
+			return protocolError{code: "missing-games", err: fmt.Errorf("cannot start games of client %v. Error: %v", client.Name, missing)}
+		} else if unregistered, ok := err.(nightfury.Unregistered); ok {
+			return protocolError{code: "unregistered", err: unregistered}
+		} else if err != nil {
+			return fmt.Errorf("cannot start games of client %v. Error: %v", client.Name, err)
 		}
 		messageGameToStart(client, firstGame)
 	case resetClient:
-		log.Infof("client '%v' has requested reset games", client.Name)
-		err := client.Reset()
+		logger.Infof("client '%v' has requested reset games", client.Name)
+		err := client.Reset(repository)
 		if err != nil {
-			log.Errorf("cannot reset client %v. Error: %v", client.Name, err)
-			return
+			return fmt.Errorf("cannot reset client %v. Error: %v", client.Name, err)
+		}
+	case registerClient:
+		registration := nightfury.Registration{}
+		if err := message.DecodePayload(&registration); err != nil {
+			return err
+		}
+		if _, err := register(repository, client, registration, actor); err != nil {
+			return protocolError{code: "invalid-registration", err: fmt.Errorf("cannot register on client %v. Error: %v", client.Name, err)}
+		}
+	case chooseClient:
+		choice := Choice{}
+		if err := message.DecodePayload(&choice); err != nil {
+			return err
+		}
+		logger.WithFields(log.Fields{log.GameField: choice.Game}).Infof("client '%v' has chosen to play '%v'", client.Name, choice.Game)
+		game, err := client.Choose(repository, choice.Game)
+		if err != nil {
+			return fmt.Errorf("cannot choose game of client %v. Error: %v", client.Name, err)
+		}
+		handleGameStarted(client, game)
+		messageGameToStart(client, game)
+	case pauseClient:
+		logger.Infof("client '%v' has requested to pause", client.Name)
+		if _, err := pause(repository, client); err != nil {
+			return fmt.Errorf("cannot pause client %v. Error: %v", client.Name, err)
+		}
+	case resumeClient:
+		logger.Infof("client '%v' has requested to resume", client.Name)
+		if _, err := resume(repository, client); err != nil {
+			return fmt.Errorf("cannot resume client %v. Error: %v", client.Name, err)
 		}
 	default:
-		err := fmt.Errorf("unknown action '%v' from client '%v'", message.Action, client.Name)
-		logErr(err)
+		return protocolError{code: "unknown-action", err: fmt.Errorf("unknown action '%v' from client '%v'", message.Action, client.Name)}
 	}
+	return nil
 }
 
 func messageGameToStart(client nightfury.Client, game nightfury.Game) {
-	message := Message{Action: startClient, Payload: game}
-	broadcastMessageToGame(client, game, message)
+	broadcastMessageToGame(client, game, startClient, game)
+}
+
+// offerChoices asks the client to choose the branch to play next
+func offerChoices(repository db.Repository, client nightfury.Client, names []string) {
+	choices := Choices{}
+	for _, name := range names {
+		game, err := nightfury.NewGameFromRepoWithName(repository, name)
+		if err != nil {
+			logErr(err)
+			game = nightfury.Game{Name: name}
+		}
+		choices.Games = append(choices.Games, game)
+	}
+	clientLogger(client).Infof("client '%v' has been offered to choose from %v", client.Name, names)
+	broadcastMessageToClient(client, offerChoice, choices)
 }
 
 func clientFromSession(session *melody.Session, notFoundFn func(id string) (nightfury.Client, error)) (*nightfury.Client, db.Repository, error) {
@@ -0,0 +1,91 @@
+package socket
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"gopkg.in/olahol/melody.v1"
+	"sort"
+	"sync"
+)
+
+// connections counts the open game sockets by client and game
+var connections = map[string]map[string]int{}
+var trackedSessions = map[*melody.Session]bool{}
+var connectionsLock = new(sync.Mutex)
+
+func trackConnection(session *melody.Session, client nightfury.Client, game nightfury.Game) {
+	connectionsLock.Lock()
+	defer connectionsLock.Unlock()
+	if connections[client.Name] == nil {
+		connections[client.Name] = map[string]int{}
+	}
+	connections[client.Name][game.Name]++
+	trackedSessions[session] = true
+}
+
+func untrackConnection(session *melody.Session, client nightfury.Client, game nightfury.Game) {
+	connectionsLock.Lock()
+	defer connectionsLock.Unlock()
+	if !trackedSessions[session] {
+		return
+	}
+	delete(trackedSessions, session)
+	connections[client.Name][game.Name]--
+	if connections[client.Name][game.Name] <= 0 {
+		delete(connections[client.Name], game.Name)
+	}
+}
+
+// ConnectedGames returns the names of the games connected on the clients
+func ConnectedGames(clientIDs ...string) []string {
+	connectionsLock.Lock()
+	defer connectionsLock.Unlock()
+	names := map[string]bool{}
+	for _, id := range clientIDs {
+		for name := range connections[id] {
+			names[name] = true
+		}
+	}
+	connected := make([]string, 0, len(names))
+	for name := range names {
+		connected = append(connected, name)
+	}
+	sort.Strings(connected)
+	return connected
+}
+
+// connectedGamesOf returns the games connected on the client and its team members
+func connectedGamesOf(client nightfury.Client) []string {
+	var members []string
+	for name := range teamMembers(client) {
+		members = append(members, name)
+	}
+	return ConnectedGames(members...)
+}
+
+// AssignGames configures the games played by the client, creating the client if it has not connected yet
+func AssignGames(repo db.Repository, id string, games []nightfury.Game, actor nightfury.Actor) (nightfury.Client, error) {
+	if !acquire() {
+		return nightfury.Client{}, fmt.Errorf("server is shutting down")
+	}
+	defer release()
+	client, err := nightfury.NewClientFromRepoWithName(repo, id)
+	if _, ok := err.(db.EntryNotFound); ok {
+		client, err = nightfury.NewClient(id, false), nil
+	}
+	if err != nil {
+		return client, err
+	}
+	before := client.GameStatuses
+	client, err = client.Assign(games...)
+	if err != nil {
+		return client, err
+	}
+	clientLogger(client).Infof("client '%v' has been assigned games %v", client.Name, client.Games)
+	if err := client.Save(repo); err != nil {
+		return client, err
+	}
+	recordChanges(repo, client, before, actor, assignGames)
+	return client, nil
+}
@@ -0,0 +1,111 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAssignedGames(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	defer ConfigureResumeWindow(resumeWindow)
+	repository := db.DefaultRepository()
+	snakes := nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}
+	seeker := nightfury.Game{Name: "seeker", Instruction: "instruction", Type: "web"}
+	_ = snakes.Save(repository)
+	_ = seeker.Save(repository)
+
+	_, err := AssignGames(db.DefaultRepository(), "kiosk-1", []nightfury.Game{snakes, seeker}, nightfury.Actor{})
+	assert.NoError(t, err)
+
+	clientConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer clientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+	snakesConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+	defer func() { _ = snakesConn.Close() }()
+	time.Sleep(50 * time.Millisecond)
+
+	var seekerConn *websocket.Conn
+	defer func() {
+		if seekerConn != nil {
+			_ = seekerConn.Close()
+		}
+	}()
+
+	t.Run("should refuse to start until every assigned game is connected", func(t *testing.T) {
+		message, _ := NewMessage(startClient, nil)
+		_ = clientConn.WriteJSON(message)
+
+		actual := readMessage(t, clientConn)
+		payload := ErrorPayload{}
+		_ = actual.DecodePayload(&payload)
+
+		assert.Equal(t, messageError, actual.Action)
+		assert.Equal(t, "missing-games", payload.Code)
+		assert.Equal(t, "cannot start games of client kiosk-1. Error: games seeker are not connected", payload.Message)
+	})
+
+	t.Run("should not add games which are not assigned", func(t *testing.T) {
+		smileConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/smile")
+		defer smileConn.Close()
+		time.Sleep(50 * time.Millisecond)
+
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+
+		_, ok := client.GameStatuses["smile"]
+		assert.False(t, ok)
+		assert.Equal(t, []string{"snakes"}, ConnectedGames("kiosk-1"))
+	})
+
+	t.Run("should start once every assigned game is connected", func(t *testing.T) {
+		seekerConn = dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/seeker")
+		assert.Equal(t, gameResumed, readMessage(t, seekerConn).Action)
+		message, _ := NewMessage(startClient, nil)
+		_ = clientConn.WriteJSON(message)
+
+		assert.Equal(t, messageAck, readMessage(t, clientConn).Action)
+		assert.Equal(t, startClient, readMessage(t, seekerConn).Action)
+	})
+
+	t.Run("should keep assigned games which disconnect", func(t *testing.T) {
+		_ = snakesConn.Close()
+		time.Sleep(50 * time.Millisecond)
+
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+
+		assert.Equal(t, nightfury.Ready, client.GameStatuses["snakes"].Status)
+		assert.Equal(t, []string{"seeker"}, ConnectedGames("kiosk-1"))
+	})
+
+	t.Run("should resume assigned games reconnecting within the resume window", func(t *testing.T) {
+		snakesConn = dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+
+		assert.Equal(t, gameResumed, readMessage(t, snakesConn).Action)
+		assert.ElementsMatch(t, []string{"seeker", "snakes"}, ConnectedGames("kiosk-1"))
+	})
+
+	t.Run("should keep assigned games past the resume window", func(t *testing.T) {
+		ConfigureResumeWindow(50 * time.Millisecond)
+		_ = seekerConn.Close()
+		seekerConn = nil
+		time.Sleep(200 * time.Millisecond)
+
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+
+		assert.Equal(t, nightfury.InProgress, client.GameStatuses["seeker"].Status)
+	})
+
+	t.Run("should resume assigned games reconnecting past the resume window", func(t *testing.T) {
+		seekerConn = dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/seeker")
+
+		resumed := readMessage(t, seekerConn)
+		state := ResumeState{}
+		_ = resumed.DecodePayload(&state)
+		assert.Equal(t, gameResumed, resumed.Action)
+		assert.Equal(t, nightfury.InProgress, state.Status.Status)
+	})
+}
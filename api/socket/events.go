@@ -0,0 +1,22 @@
+package socket
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+)
+
+// ActivateEvent activates the event and scopes the sockets to its data, holding the socket lock
+// so no socket handler sees the scope change halfway
+func ActivateEvent(event nightfury.Event) (nightfury.Event, error) {
+	if !acquire() {
+		return event, fmt.Errorf("server is shutting down")
+	}
+	defer release()
+	activated, err := event.Activate(db.GlobalRepository())
+	if err != nil {
+		return activated, err
+	}
+	db.ActivateScope(activated.ID())
+	return activated, nil
+}
@@ -1,7 +1,6 @@
 package socket
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/boothgames/nightfury/log"
 	"github.com/boothgames/nightfury/pkg/db"
@@ -9,21 +8,37 @@ import (
 	"github.com/gin-gonic/gin"
 	"gopkg.in/olahol/melody.v1"
 	"net/http"
+	"time"
 )
 
 const (
 	gameStarted   = "started"
 	gameCompleted = "completed"
 	gameFailed    = "failed"
+	gameProgress  = "progress"
+
+	connectGame = "connect"
+	removeGame  = "remove"
+	assignGames = "assign"
 )
 
+// GameProgress represents the progress of a game relayed to the client socket and its spectators
+type GameProgress struct {
+	Game     string             `json:"game"`
+	Progress nightfury.Progress `json:"progress"`
+}
+
 // HandleGames handle socket connection related to games
 func HandleGames(c *gin.Context) {
 	clientID := c.Param("id")
 	gameName := c.Param("name")
+	if !authorized(c, clientID, gameName) {
+		return
+	}
 	err := gameEngine.HandleRequestWithKeys(c.Writer, c.Request, map[string]interface{}{
 		socketGameID:   gameName,
 		socketClientID: clientID,
+		socketRequest:  c.GetString(log.RequestIDField),
 	})
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
@@ -31,10 +46,10 @@ func HandleGames(c *gin.Context) {
 }
 
 func gameConnected(session *melody.Session) {
-	lock.Lock()
-	defer func() {
-		lock.Unlock()
-	}()
+	if !acquire() {
+		return
+	}
+	defer release()
 	client, _, err := clientFromSession(session, func(id string) (client nightfury.Client, e error) {
 		return nightfury.Client{}, fmt.Errorf("client not found")
 	})
@@ -45,7 +60,7 @@ func gameConnected(session *melody.Session) {
 		return
 	}
 	if !client.Available {
-		log.Error("client not available, closing session")
+		sessionLogger(session).Warn("client not available, closing session")
 		err := session.Close()
 		logErr(err)
 		return
@@ -57,17 +72,31 @@ func gameConnected(session *melody.Session) {
 		logErr(err)
 		return
 	}
+	if !client.Plays(game.Name) {
+		sessionLogger(session).Warnf("game '%v' is not assigned to client '%v', closing session", game.Name, client.Name)
+		err := session.Close()
+		logErr(err)
+		return
+	}
+	trackConnection(session, *client, *game)
+	cancelRemoval(*client, *game)
+	if _, ok := client.GameStatuses[game.Name]; ok {
+		resumeGame(session, *client, *game)
+		return
+	}
+	before := client.GameStatuses.Copy()
 	client.Add(*game)
 	err = client.Save(repository)
 	logErr(err)
-	log.Infof("game '%v' of client '%v' connected", game.Name, client.Name)
+	recordChanges(repository, *client, before, sessionActor(session), connectGame)
+	sessionLogger(session).Infof("game '%v' of client '%v' connected", game.Name, client.Name)
 }
 
 func gameDisconnected(session *melody.Session) {
-	lock.Lock()
-	defer func() {
-		lock.Unlock()
-	}()
+	if !acquire() {
+		return
+	}
+	defer release()
 	client, _, err := clientFromSession(session, func(id string) (client nightfury.Client, e error) {
 		return nightfury.Client{}, fmt.Errorf("client not found")
 	})
@@ -75,29 +104,34 @@ func gameDisconnected(session *melody.Session) {
 		logErr(err)
 		return
 	}
-	game, repository, err := gameFromSession(session, func(id string) (nightfury.Game, error) {
+	game, _, err := gameFromSession(session, func(id string) (nightfury.Game, error) {
 		return nightfury.Game{Name: id}, nil
 	})
 	if err != nil {
 		logErr(err)
 		return
 	}
-	client.Remove(*game)
-	err = client.Save(repository)
-	logErr(err)
-	log.Infof("game '%v' of client '%v' disconnected", game.Name, client.Name)
+	untrackConnection(session, *client, *game)
+	scheduleRemoval(*client, *game)
+	sessionLogger(session).Infof("game '%v' of client '%v' disconnected, keeping its status for %v", game.Name, client.Name, resumeWindow)
 }
 
 func gameMessageReceived(session *melody.Session, data []byte) {
-	lock.Lock()
-	defer func() {
-		lock.Unlock()
-	}()
-	client, _, err := clientFromSession(session, func(id string) (client nightfury.Client, e error) {
+	if !acquire() {
+		return
+	}
+	defer release()
+	message, err := ParseMessage(data)
+	if err != nil {
+		reply(session, message, err)
+		return
+	}
+
+	client, repository, err := clientFromSession(session, func(id string) (client nightfury.Client, e error) {
 		return nightfury.Client{}, fmt.Errorf("client not found")
 	})
 	if err != nil {
-		logErr(err)
+		reply(session, message, err)
 		return
 	}
 
@@ -105,68 +139,98 @@ func gameMessageReceived(session *melody.Session, data []byte) {
 		return nightfury.Game{}, fmt.Errorf("game %v not found", name)
 	})
 	if err != nil {
-		logErr(err)
+		reply(session, message, err)
 		return
 	}
 
-	message := Message{}
-	err = json.Unmarshal(data, &message)
-	if err != nil {
-		logErr(err)
+	seenClient := client.Seen(time.Now())
+	if err := seenClient.Save(repository); err != nil {
+		reply(session, message, err)
 		return
 	}
-	processGameMessage(*client, *game, message)
+	sessionLogger(session).WithFields(log.Fields{log.ActionField: message.Action}).
+		Debugf("game '%v' of client '%v' sent %v", game.Name, seenClient.Name, message.Action)
+	before := seenClient.GameStatuses.Copy()
+	err = processGameMessage(repository, seenClient, *game, message)
+	recordChanges(repository, seenClient, before, sessionActor(session), message.Action)
+	reply(session, message, err)
 }
 
-func processGameMessage(client nightfury.Client, game nightfury.Game, message Message) {
+func processGameMessage(repository db.Repository, client nightfury.Client, game nightfury.Game, message Message) error {
 	switch message.Action {
 	case gameStarted:
 		handleGameStarted(client, game)
+		return nil
+	case gameProgress:
+		progress := nightfury.Progress{}
+		if err := message.DecodePayload(&progress); err != nil {
+			return err
+		}
+		return handleGameProgress(repository, client, game, progress)
 	case gameCompleted:
-		handleGameCompleted(client, game)
+		if len(message.Payload) > 0 {
+			progress := nightfury.Progress{}
+			if err := message.DecodePayload(&progress); err != nil {
+				return err
+			}
+			if err := handleGameProgress(repository, client, game, progress); err != nil {
+				return err
+			}
+		}
+		return handleGameCompleted(repository, client, game)
 	case gameFailed:
-		handleGameFailed(client, game)
+		return handleGameFailed(repository, client, game)
 	default:
-		err := fmt.Errorf("unknown action '%v' from game '%v' of client '%v'", message.Action, game.Name, client.Name)
-		logErr(err)
+		return protocolError{code: "unknown-action", err: fmt.Errorf("unknown action '%v' from game '%v' of client '%v'", message.Action, game.Name, client.Name)}
 	}
 }
 
-func handleGameFailed(client nightfury.Client, game nightfury.Game) {
-	log.Infof("game '%v' of client '%v' has failed", game.Name, client.Name)
-	if err := client.FailGame(game); err != nil {
-		logErr(err)
-		return
+func handleGameProgress(repository db.Repository, client nightfury.Client, game nightfury.Game, progress nightfury.Progress) error {
+	gameLogger(client, game).Debugf("game '%v' of client '%v' is at %v%%", game.Name, client.Name, progress.Percentage)
+	if err := client.UpdateProgress(repository, game, progress); err != nil {
+		return err
 	}
-	message := Message{Action: gameFailed, Payload: game}
-	broadcastMessageToClient(client, message)
+	broadcastMessageToClient(client, gameProgress, GameProgress{Game: game.Name, Progress: progress})
+	return nil
 }
 
-func handleGameCompleted(client nightfury.Client, game nightfury.Game) {
-	log.Infof("game '%v' of client '%v' has completed playing", game.Name, client.Name)
-	if err := client.CompleteGame(game); err != nil {
-		logErr(err)
-		return
+func handleGameFailed(repository db.Repository, client nightfury.Client, game nightfury.Game) error {
+	gameLogger(client, game).Infof("game '%v' of client '%v' has failed", game.Name, client.Name)
+	if err := client.FailGame(repository, game); err != nil {
+		return err
 	}
-	message := Message{Action: gameCompleted, Payload: game}
-	broadcastMessageToClient(client, message)
+	broadcastMessageToClient(client, gameFailed, game)
+	recordRaceResult(repository, client, game, true)
+	return nil
+}
+
+func handleGameCompleted(repository db.Repository, client nightfury.Client, game nightfury.Game) error {
+	gameLogger(client, game).Infof("game '%v' of client '%v' has completed playing", game.Name, client.Name)
+	if err := client.CompleteGame(repository, game); err != nil {
+		return err
+	}
+	broadcastMessageToClient(client, gameCompleted, game)
+	recordRaceResult(repository, client, game, false)
 
 	if client.HasNext() {
-		nextGame, err := client.Next()
+		if choices := client.GameStatuses.Choices(); len(choices) > 1 {
+			offerChoices(repository, client, choices)
+			return nil
+		}
+		nextGame, err := client.Next(repository)
 		if err != nil {
 			logErr(err)
-			return
+			return nil
 		}
 		handleGameStarted(client, nextGame)
 		messageGameToStart(client, nextGame)
-		return
 	}
+	return nil
 }
 
 func handleGameStarted(client nightfury.Client, game nightfury.Game) {
-	log.Infof("game '%v' of client '%v' has started playing", game.Name, client.Name)
-	message := Message{Action: gameStarted, Payload: game}
-	broadcastMessageToClient(client, message)
+	gameLogger(client, game).Infof("game '%v' of client '%v' has started playing", game.Name, client.Name)
+	broadcastMessageToClient(client, gameStarted, game)
 }
 
 func gameFromSession(session *melody.Session, notFoundFn func(name string) (nightfury.Game, error)) (*nightfury.Game, db.Repository, error) {
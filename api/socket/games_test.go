@@ -0,0 +1,59 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGameProgress(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+
+	clientConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer clientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+	spectatorConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/spectators")
+	defer spectatorConn.Close()
+	gameConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+	defer gameConn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+	client.GameStatuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.InProgress}
+	_ = client.Save(repository)
+
+	progress := nightfury.Progress{Percentage: 25, Score: 40, Stats: map[string]interface{}{"lives": float64(3)}}
+	message, _ := NewMessage(gameProgress, progress)
+
+	t.Run("should relay progress to client and spectators", func(t *testing.T) {
+		_ = gameConn.WriteJSON(message)
+
+		assert.Equal(t, messageAck, readMessage(t, gameConn).Action)
+		for _, actual := range []Message{readMessage(t, clientConn), readMessage(t, spectatorConn)} {
+			relayed := GameProgress{}
+			_ = actual.DecodePayload(&relayed)
+			assert.Equal(t, gameProgress, actual.Action)
+			assert.Equal(t, GameProgress{Game: "snakes", Progress: progress}, relayed)
+		}
+	})
+
+	t.Run("should store progress on the game status", func(t *testing.T) {
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+
+		assert.Equal(t, &progress, actual.GameStatuses["snakes"].Progress)
+	})
+
+	t.Run("should reject messages from spectators", func(t *testing.T) {
+		_ = spectatorConn.WriteJSON(message)
+
+		actual := readMessage(t, spectatorConn)
+
+		assert.Equal(t, messageError, actual.Action)
+		assert.Equal(t, message.ID, actual.CorrelationID)
+	})
+}
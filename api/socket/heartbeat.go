@@ -0,0 +1,94 @@
+package socket
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/olahol/melody.v1"
+	"time"
+)
+
+// ConfigureHeartbeat pings every client and game socket at interval and
+// closes the ones which have not answered within timeout
+func ConfigureHeartbeat(interval, timeout time.Duration) {
+	for _, engine := range engines() {
+		engine.Config.PingPeriod = interval
+		engine.Config.PongWait = timeout
+	}
+}
+
+func clientPongReceived(session *melody.Session) {
+	touchClient(session, func(client nightfury.Client) nightfury.Client {
+		return client.Connected().Seen(time.Now())
+	})
+}
+
+func gamePongReceived(session *melody.Session) {
+	touchClient(session, func(client nightfury.Client) nightfury.Client {
+		return client.Seen(time.Now())
+	})
+}
+
+func touchClient(session *melody.Session, touchFn func(nightfury.Client) nightfury.Client) {
+	if !acquire() {
+		return
+	}
+	defer release()
+
+	client, repository, err := clientFromSession(session, func(id string) (nightfury.Client, error) {
+		return nightfury.Client{}, fmt.Errorf("client %v not found", id)
+	})
+	if err != nil {
+		logErr(err)
+		return
+	}
+	err = touchFn(*client).SavePresence(repository)
+	logErr(err)
+}
+
+// StartReaper checks every interval for clients which have not been seen within staleAfter,
+// until the sockets are shut down
+func StartReaper(interval, staleAfter time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if !acquire() {
+			return
+		}
+		reapStaleClients(time.Now(), staleAfter)
+		release()
+	}
+}
+
+// reapStaleClients marks the stale clients as unavailable and pauses their game in progress
+func reapStaleClients(now time.Time, staleAfter time.Duration) {
+	repository := db.DefaultRepository()
+	clientsFromRepo, err := nightfury.NewClientsFromRepo(repository)
+	if err != nil {
+		logErr(err)
+		return
+	}
+	clients := nightfury.Clients{}
+	if err := mapstructure.Decode(clientsFromRepo, &clients); err != nil {
+		logErr(err)
+		return
+	}
+
+	for _, client := range clients {
+		if !client.IsStale(now, staleAfter) {
+			continue
+		}
+		clientLogger(client).Warnf("client '%v' not seen since %v, marking it unavailable", client.Name, client.LastSeen)
+		if _, ok := client.GameStatuses.InProgressGame(); ok {
+			before := client.GameStatuses.Copy()
+			if _, err := pause(repository, client); err != nil {
+				logErr(err)
+				continue
+			}
+			recordChanges(repository, client, before, reaperActor, pauseClient)
+		}
+		err := client.Disconnected().Save(repository)
+		logErr(err)
+	}
+}
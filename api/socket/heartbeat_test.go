@@ -0,0 +1,43 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReapStaleClients(t *testing.T) {
+	_, teardown := setupSocketTestContext(t)
+	defer teardown()
+
+	now := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	repository := db.DefaultRepository()
+	stale := nightfury.NewClient("stale", true,
+		nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed},
+		nightfury.GameStatus{Name: "smile", Status: nightfury.InProgress},
+	).Seen(now.Add(-2 * time.Minute))
+	fresh := nightfury.NewClient("fresh", true,
+		nightfury.GameStatus{Name: "smile", Status: nightfury.InProgress},
+	).Seen(now.Add(-10 * time.Second))
+	_ = stale.Save(repository)
+	_ = fresh.Save(repository)
+
+	reapStaleClients(now, time.Minute)
+
+	t.Run("should mark stale client unavailable and pause its game in progress", func(t *testing.T) {
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "stale")
+
+		assert.False(t, actual.Available)
+		assert.Equal(t, nightfury.Paused, actual.GameStatuses["smile"].Status)
+		assert.Equal(t, nightfury.Completed, actual.GameStatuses["snakes"].Status)
+	})
+
+	t.Run("should leave recently seen client untouched", func(t *testing.T) {
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "fresh")
+
+		assert.True(t, actual.Available)
+		assert.Equal(t, nightfury.InProgress, actual.GameStatuses["smile"].Status)
+	})
+}
@@ -0,0 +1,78 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/boothgames/nightfury/pkg/webhook"
+	"gopkg.in/olahol/melody.v1"
+	"time"
+)
+
+// recordChanges observes the gameplay events turning the statuses before into the current statuses of
+// the client, once they have been saved along with its statuses, rewards the client completing its run,
+// notifies the webhooks when the client completes, fails or resets, and audits the change along with
+// the actor and the action which made it
+func recordChanges(repository db.Repository, client nightfury.Client, before nightfury.GameStatuses, actor nightfury.Actor, action string) {
+	now := time.Now()
+	events := nightfury.Changes(before, client.GameStatuses, now)
+	if len(events) == 0 {
+		return
+	}
+
+	history, err := nightfury.NewHistoryFromRepoWithName(repository, client.Name)
+	if err != nil {
+		logErr(err)
+		return
+	}
+	observeEvents(history, events)
+	for _, event := range nightfury.Notifications(before, client, events) {
+		notification := nightfury.NewNotification(event, client, now)
+		if event == nightfury.ClientCompletedNotification {
+			notification.Reward = allocateReward(repository, client, history, now)
+		}
+		webhook.DefaultDispatcher().Dispatch(db.GlobalRepository(), notification)
+	}
+
+	if onlyProgress(events) {
+		return
+	}
+	auditChange(repository, actor, action, "client", client.Name, client.Name, before, client.GameStatuses)
+}
+
+// auditChange appends the change concerning the client to the audit log, a failure to audit it is only logged
+func auditChange(repository db.Repository, actor nightfury.Actor, action, entity, name, client string, before, after interface{}) {
+	entry, err := nightfury.NewAuditEntry(time.Now(), actor, action, entity, name, before, after)
+	if err != nil {
+		logErr(err)
+		return
+	}
+	logErr(entry.ForClient(client).Save(repository))
+}
+
+func onlyProgress(events []nightfury.GameplayEvent) bool {
+	for _, event := range events {
+		if event.Type != nightfury.GameProgress {
+			return false
+		}
+	}
+	return true
+}
+
+// sessionActor returns the client or game socket of the session as the actor
+func sessionActor(session *melody.Session) nightfury.Actor {
+	actor := nightfury.Actor{Type: nightfury.ClientSocketActor}
+	if id, ok := clientID(session); ok {
+		actor.ID = id
+	}
+	if name, ok := gameName(session); ok {
+		actor.Type = nightfury.GameSocketActor
+		actor.ID = actor.ID + "/" + name
+	}
+	if session != nil {
+		actor.RequestID, _ = session.Keys[socketRequest].(string)
+	}
+	return actor
+}
+
+// reaperActor represents the server marking stale clients unavailable and removing games which did not resume
+var reaperActor = nightfury.Actor{Type: nightfury.ServerActor, ID: "reaper"}
@@ -0,0 +1,87 @@
+package socket
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGameplayHistory(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+
+	clientConn := connectSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer clientConn.Close()
+	gameConn := connectSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+	defer gameConn.Close()
+
+	start, _ := NewMessage(startClient, nil)
+	_ = clientConn.WriteJSON(start)
+	assert.Equal(t, startClient, readMessage(t, gameConn).Action)
+	completed, _ := NewMessage(gameCompleted, nightfury.Progress{Percentage: 100, Score: 40})
+	_ = gameConn.WriteJSON(completed)
+	assert.Equal(t, messageAck, readMessage(t, gameConn).Action)
+
+	t.Run("should record the gameplay events in order", func(t *testing.T) {
+		history, _ := nightfury.NewHistoryFromRepoWithName(repository, "kiosk-1")
+
+		var types []string
+		for _, event := range history.Events {
+			types = append(types, event.Type)
+		}
+		assert.Equal(t, []string{
+			nightfury.GameAdded, nightfury.RunStarted, nightfury.GameStarted, nightfury.GameProgress, nightfury.GameCompleted,
+		}, types)
+	})
+
+	t.Run("should derive the statuses of the games from the events", func(t *testing.T) {
+		history, _ := nightfury.NewHistoryFromRepoWithName(repository, "kiosk-1")
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+
+		assert.Equal(t, client.GameStatuses, history.Replay())
+	})
+
+	t.Run("should refuse to delete the connected client", func(t *testing.T) {
+		_, err := DeleteClient(db.DefaultRepository(), "kiosk-1")
+
+		assert.EqualError(t, err, "client kiosk-1 is connected")
+	})
+}
+
+func TestAuditClientChanges(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+
+	clientConn := connectSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer clientConn.Close()
+	gameConn := connectSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+	defer gameConn.Close()
+
+	start, _ := NewMessage(startClient, nil)
+	_ = clientConn.WriteJSON(start)
+	assert.Equal(t, startClient, readMessage(t, gameConn).Action)
+	failed, _ := NewMessage(gameFailed, nil)
+	_ = gameConn.WriteJSON(failed)
+	assert.Equal(t, messageAck, readMessage(t, gameConn).Action)
+
+	t.Run("should audit the changes along with the socket which made them", func(t *testing.T) {
+		entries, _ := nightfury.NewAuditEntriesFromRepo(repository, nightfury.AuditFilter{Client: "kiosk-1"})
+
+		if assert.Len(t, entries, 3) {
+			assert.Equal(t, connectGame, entries[0].Action)
+			assert.Equal(t, startClient, entries[1].Action)
+			assert.Equal(t, nightfury.Actor{Type: nightfury.ClientSocketActor, ID: "kiosk-1"}, entries[1].Actor)
+			assert.Equal(t, gameFailed, entries[2].Action)
+			assert.Equal(t, nightfury.Actor{Type: nightfury.GameSocketActor, ID: "kiosk-1/snakes"}, entries[2].Actor)
+			before := nightfury.GameStatuses{}
+			_ = json.Unmarshal(entries[2].Before, &before)
+			assert.Equal(t, nightfury.InProgress, before["snakes"].Status)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package socket
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/boothgames/nightfury/log"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/olahol/melody.v1"
+	"testing"
+)
+
+func TestSessionLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	restore := log.SetOutput(out)
+	defer restore()
+	_ = log.SetLogFormat("json")
+	defer func() { _ = log.SetLogFormat("text") }()
+	session := &melody.Session{Keys: map[string]interface{}{
+		socketClientID: "kiosk-1",
+		socketGameID:   "snakes",
+		socketRequest:  "req-42",
+	}}
+
+	sessionLogger(session).Info("game connected")
+
+	actual := map[string]interface{}{}
+	_ = json.Unmarshal(out.Bytes(), &actual)
+	assert.Equal(t, "kiosk-1", actual[log.ClientField])
+	assert.Equal(t, "snakes", actual[log.GameField])
+	assert.Equal(t, "req-42", actual[log.RequestIDField])
+}
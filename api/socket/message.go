@@ -1,9 +1,100 @@
 package socket
 
-import "github.com/boothgames/nightfury/pkg/db"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion represents the version of the socket protocol spoken by the server.
+// Messages without a version are treated as version 1, which had no ids or replies
+const ProtocolVersion = 2
+
+const (
+	messageAck   = "ack"
+	messageError = "error"
+
+	malformedMessage = "malformed"
+)
 
 // Message represents message sent across ws
 type Message struct {
-	Action  string   `json:"action"`
-	Payload db.Model `json:"payload"`
+	Version       int             `json:"version"`
+	ID            string          `json:"id,omitempty"`
+	CorrelationID string          `json:"correlationId,omitempty"`
+	Action        string          `json:"action"`
+	Payload       json.RawMessage `json:"payload,omitempty"`
+}
+
+// ErrorPayload represents the payload of an error reply
+type ErrorPayload struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewMessage returns a message of the current protocol version carrying the payload
+func NewMessage(action string, payload interface{}) (Message, error) {
+	message := Message{Version: ProtocolVersion, ID: newMessageID(), Action: action}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return message, fmt.Errorf("unable to marshal payload of '%v', reason %v", action, err)
+		}
+		message.Payload = data
+	}
+	return message, nil
+}
+
+// ParseMessage unmarshals a message received over the socket
+func ParseMessage(data []byte) (Message, error) {
+	message := Message{}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return message, protocolError{code: malformedMessage, err: fmt.Errorf("malformed message, reason %v", err)}
+	}
+	if message.Version > ProtocolVersion {
+		return message, protocolError{code: "unsupported-version", err: fmt.Errorf("unsupported protocol version %v", message.Version)}
+	}
+	return message, nil
+}
+
+// DecodePayload unmarshals the message payload into v
+func (m Message) DecodePayload(v interface{}) error {
+	if len(m.Payload) == 0 {
+		return protocolError{code: "invalid-payload", err: fmt.Errorf("'%v' requires a payload", m.Action)}
+	}
+	if err := json.Unmarshal(m.Payload, v); err != nil {
+		return protocolError{code: "invalid-payload", err: fmt.Errorf("invalid payload for '%v', reason %v", m.Action, err)}
+	}
+	return nil
+}
+
+// Reply returns the message answering m, correlated by the id of m
+func (m Message) Reply(action string, payload interface{}) (Message, error) {
+	reply, err := NewMessage(action, payload)
+	reply.CorrelationID = m.ID
+	return reply, err
+}
+
+// protocolError represents an error reported back to the sender with a code
+type protocolError struct {
+	code string
+	err  error
+}
+
+func (e protocolError) Error() string {
+	return e.err.Error()
+}
+
+func errorCode(err error) string {
+	if protocolErr, ok := err.(protocolError); ok {
+		return protocolErr.code
+	}
+	return "rejected"
+}
+
+func newMessageID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
 }
@@ -0,0 +1,116 @@
+package socket
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestParseMessage(t *testing.T) {
+	t.Run("should parse message of the current version", func(t *testing.T) {
+		message, err := ParseMessage([]byte(`{"version":2,"id":"1","action":"start"}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Message{Version: 2, ID: "1", Action: "start"}, message)
+	})
+
+	t.Run("should parse version 1 message without version", func(t *testing.T) {
+		message, err := ParseMessage([]byte(`{"action":"start"}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Message{Action: "start"}, message)
+	})
+
+	t.Run("should reject message of a newer version", func(t *testing.T) {
+		_, err := ParseMessage([]byte(`{"version":3,"action":"start"}`))
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "unsupported protocol version 3", err.Error())
+			assert.Equal(t, "unsupported-version", errorCode(err))
+		}
+	})
+
+	t.Run("should reject malformed message", func(t *testing.T) {
+		_, err := ParseMessage([]byte(`start`))
+
+		if assert.Error(t, err) {
+			assert.Equal(t, malformedMessage, errorCode(err))
+		}
+	})
+}
+
+func TestMessageDecodePayload(t *testing.T) {
+	t.Run("should decode payload", func(t *testing.T) {
+		message, _ := NewMessage(messageError, ErrorPayload{Code: "code", Message: "message"})
+		actual := ErrorPayload{}
+
+		err := message.DecodePayload(&actual)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ErrorPayload{Code: "code", Message: "message"}, actual)
+	})
+
+	t.Run("should fail when payload is missing", func(t *testing.T) {
+		message, _ := NewMessage(messageError, nil)
+
+		err := message.DecodePayload(&ErrorPayload{})
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "invalid-payload", errorCode(err))
+		}
+	})
+}
+
+func TestMessageReply(t *testing.T) {
+	message, _ := NewMessage(startClient, nil)
+
+	actual, err := message.Reply(messageAck, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersion, actual.Version)
+	assert.Equal(t, message.ID, actual.CorrelationID)
+	assert.NotEqual(t, message.ID, actual.ID)
+}
+
+func TestSchema(t *testing.T) {
+	t.Run("should match the generated protocol schema", func(t *testing.T) {
+		expected, err := ioutil.ReadFile("../../docs/protocol.schema.json")
+		assert.NoError(t, err)
+
+		actual, _ := json.MarshalIndent(Schema(), "", "  ")
+
+		assert.JSONEq(t, string(expected), string(actual), "run `make schema` to regenerate docs/protocol.schema.json")
+	})
+}
+
+func TestReply(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+
+	clientConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer clientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	t.Run("should ack a processed message", func(t *testing.T) {
+		_ = clientConn.WriteJSON(Message{Version: ProtocolVersion, ID: "reset-1", Action: resetClient})
+
+		actual := readMessage(t, clientConn)
+
+		assert.Equal(t, messageAck, actual.Action)
+		assert.Equal(t, "reset-1", actual.CorrelationID)
+	})
+
+	t.Run("should reply error to the sender", func(t *testing.T) {
+		_ = clientConn.WriteJSON(Message{Version: ProtocolVersion, ID: "dance-1", Action: "dance"})
+
+		actual := readMessage(t, clientConn)
+		payload := ErrorPayload{}
+		_ = actual.DecodePayload(&payload)
+
+		assert.Equal(t, messageError, actual.Action)
+		assert.Equal(t, "dance-1", actual.CorrelationID)
+		assert.Equal(t, ErrorPayload{Code: "unknown-action", Message: "unknown action 'dance' from client 'kiosk-1'"}, payload)
+	})
+}
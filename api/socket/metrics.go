@@ -0,0 +1,52 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/metrics"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/olahol/melody.v1"
+)
+
+var sessionsDesc = prometheus.NewDesc("nightfury_socket_sessions", "Number of connected sockets.", []string{"engine"}, nil)
+
+func init() {
+	metrics.MustRegister(sessionsCollector{})
+}
+
+// sessionsCollector collects the number of sessions connected to each engine
+type sessionsCollector struct{}
+
+// Describe sends the descriptor of the sessions metric
+func (sessionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sessionsDesc
+}
+
+// Collect sends the number of sessions connected to each engine
+func (sessionsCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, engine := range map[string]*melody.Melody{"clients": clientEngine, "games": gameEngine, "spectators": spectatorEngine} {
+		ch <- prometheus.MustNewConstMetric(sessionsDesc, prometheus.GaugeValue, float64(engine.Len()), name)
+	}
+}
+
+// observeEvents counts the games started, completed and failed among the events recorded in the history,
+// along with the time it took to complete or fail them, excluding the time they were paused
+func observeEvents(history nightfury.History, events []nightfury.GameplayEvent) {
+	for _, event := range events {
+		switch event.Type {
+		case nightfury.GameStarted:
+			metrics.GamesStarted.WithLabelValues(event.Game).Inc()
+		case nightfury.GameCompleted:
+			metrics.GamesCompleted.WithLabelValues(event.Game).Inc()
+			observeDuration(history, event)
+		case nightfury.GameFailed:
+			metrics.GamesFailed.WithLabelValues(event.Game).Inc()
+			observeDuration(history, event)
+		}
+	}
+}
+
+func observeDuration(history nightfury.History, ended nightfury.GameplayEvent) {
+	if duration, ok := history.PlayDuration(ended.Game); ok {
+		metrics.GameDuration.WithLabelValues(ended.Game, ended.Status.String()).Observe(duration.Seconds())
+	}
+}
@@ -0,0 +1,63 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/metrics"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// counters returns the values of the counters, to be compared once the events are observed
+func counters(collectors ...prometheus.Collector) []float64 {
+	values := make([]float64, 0, len(collectors))
+	for _, collector := range collectors {
+		values = append(values, testutil.ToFloat64(collector))
+	}
+	return values
+}
+
+func histogram(observer prometheus.Observer) *dto.Histogram {
+	observed := &dto.Metric{}
+	_ = observer.(prometheus.Histogram).Write(observed)
+	return observed.GetHistogram()
+}
+
+func TestObserveEvents(t *testing.T) {
+	startedAt := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	events := []nightfury.GameplayEvent{
+		{Type: nightfury.GamePaused, Game: "metrics-snakes", Status: nightfury.Paused, At: startedAt.Add(time.Minute)},
+		{Type: nightfury.GameResumed, Game: "metrics-snakes", Status: nightfury.InProgress, At: startedAt.Add(2 * time.Minute)},
+		{Type: nightfury.GameCompleted, Game: "metrics-snakes", Status: nightfury.Completed, At: startedAt.Add(3 * time.Minute)},
+		{Type: nightfury.GameStarted, Game: "metrics-smile", Status: nightfury.InProgress, At: startedAt.Add(3 * time.Minute)},
+	}
+	history := nightfury.History{Client: "kiosk-1", Events: append([]nightfury.GameplayEvent{
+		{Type: nightfury.GameStarted, Game: "metrics-snakes", Status: nightfury.InProgress, At: startedAt},
+	}, events...)}
+	collectors := []prometheus.Collector{
+		metrics.GamesStarted.WithLabelValues("metrics-snakes"),
+		metrics.GamesCompleted.WithLabelValues("metrics-snakes"),
+		metrics.GamesStarted.WithLabelValues("metrics-smile"),
+		metrics.GamesFailed.WithLabelValues("metrics-snakes"),
+	}
+	before := counters(collectors...)
+	durationBefore := histogram(metrics.GameDuration.WithLabelValues("metrics-snakes", "Completed"))
+
+	observeEvents(history, events)
+
+	t.Run("should count the games started, completed and failed", func(t *testing.T) {
+		after := counters(collectors...)
+
+		assert.Equal(t, []float64{before[0], before[1] + 1, before[2] + 1, before[3]}, after)
+	})
+
+	t.Run("should observe the duration of the game excluding the time it was paused", func(t *testing.T) {
+		durationAfter := histogram(metrics.GameDuration.WithLabelValues("metrics-snakes", "Completed"))
+
+		assert.Equal(t, durationBefore.GetSampleCount()+1, durationAfter.GetSampleCount())
+		assert.Equal(t, durationBefore.GetSampleSum()+120, durationAfter.GetSampleSum())
+	})
+}
@@ -0,0 +1,72 @@
+package socket
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+)
+
+const (
+	pauseClient  = "pause"
+	resumeClient = "resume"
+	gamePaused   = "paused"
+	gameUnpaused = "resumed"
+)
+
+// PauseClient pauses the game in progress of the client and notifies its game and client sockets
+func PauseClient(repo db.Repository, id string, actor nightfury.Actor) (nightfury.Game, error) {
+	client, err := lockedClient(repo, id)
+	if err != nil {
+		return nightfury.Game{}, err
+	}
+	defer release()
+	before := client.GameStatuses.Copy()
+	defer recordChanges(repo, client, before, actor, pauseClient)
+	return pause(repo, client)
+}
+
+// ResumeClient resumes the paused game of the client and notifies its game and client sockets
+func ResumeClient(repo db.Repository, id string, actor nightfury.Actor) (nightfury.Game, error) {
+	client, err := lockedClient(repo, id)
+	if err != nil {
+		return nightfury.Game{}, err
+	}
+	defer release()
+	before := client.GameStatuses.Copy()
+	defer recordChanges(repo, client, before, actor, resumeClient)
+	return resume(repo, client)
+}
+
+// lockedClient acquires the socket lock and returns the client, the lock is released on error
+func lockedClient(repo db.Repository, id string) (nightfury.Client, error) {
+	if !acquire() {
+		return nightfury.Client{}, fmt.Errorf("server is shutting down")
+	}
+	client, err := nightfury.NewClientFromRepoWithName(repo, id)
+	if err != nil {
+		release()
+	}
+	return client, err
+}
+
+func pause(repository db.Repository, client nightfury.Client) (nightfury.Game, error) {
+	game, err := client.Pause(repository)
+	if err != nil {
+		return game, err
+	}
+	gameLogger(client, game).Infof("game '%v' of client '%v' has been paused", game.Name, client.Name)
+	broadcastMessageToGame(client, game, pauseClient, game)
+	broadcastMessageToClient(client, gamePaused, game)
+	return game, nil
+}
+
+func resume(repository db.Repository, client nightfury.Client) (nightfury.Game, error) {
+	game, err := client.Resume(repository)
+	if err != nil {
+		return game, err
+	}
+	gameLogger(client, game).Infof("game '%v' of client '%v' has been resumed", game.Name, client.Name)
+	broadcastMessageToGame(client, game, resumeClient, ResumeState{Game: game, Status: client.GameStatuses[game.Name]})
+	broadcastMessageToClient(client, gameUnpaused, game)
+	return game, nil
+}
@@ -0,0 +1,108 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPauseAndResume(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+
+	clientConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer clientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+	gameConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+	defer gameConn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+	client.GameStatuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.InProgress}
+	_ = client.Save(repository)
+
+	t.Run("should pause game on client request", func(t *testing.T) {
+		message, _ := NewMessage(pauseClient, nil)
+		_ = clientConn.WriteJSON(message)
+
+		assert.Equal(t, pauseClient, readMessage(t, gameConn).Action)
+		actions := []string{readMessage(t, clientConn).Action, readMessage(t, clientConn).Action}
+		assert.ElementsMatch(t, []string{gamePaused, messageAck}, actions)
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		assert.Equal(t, nightfury.Paused, actual.GameStatuses["snakes"].Status)
+	})
+
+	t.Run("should reject pause when no game is in progress", func(t *testing.T) {
+		message, _ := NewMessage(pauseClient, nil)
+		_ = clientConn.WriteJSON(message)
+
+		actual := readMessage(t, clientConn)
+
+		assert.Equal(t, messageError, actual.Action)
+		assert.Equal(t, message.ID, actual.CorrelationID)
+	})
+
+	t.Run("should resume paused game", func(t *testing.T) {
+		game, err := ResumeClient(db.DefaultRepository(), "kiosk-1", nightfury.Actor{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "snakes", game.Name)
+		resumed := readMessage(t, gameConn)
+		state := ResumeState{}
+		_ = resumed.DecodePayload(&state)
+		assert.Equal(t, resumeClient, resumed.Action)
+		assert.Equal(t, nightfury.InProgress, state.Status.Status)
+		assert.Equal(t, gameUnpaused, readMessage(t, clientConn).Action)
+	})
+
+	t.Run("should return entry not found for unknown client", func(t *testing.T) {
+		_, err := PauseClient(db.DefaultRepository(), "unknown", nightfury.Actor{})
+
+		_, ok := err.(db.EntryNotFound)
+		assert.True(t, ok)
+	})
+}
+
+func TestPausedGameOutlivesResumeWindow(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	defer ConfigureResumeWindow(resumeWindow)
+	ConfigureResumeWindow(50 * time.Millisecond)
+	repository := db.DefaultRepository()
+
+	clientConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer clientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+	gameConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+	time.Sleep(50 * time.Millisecond)
+
+	client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+	client.GameStatuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.Paused}
+	_ = client.Save(repository)
+
+	_ = gameConn.Close()
+	time.Sleep(200 * time.Millisecond)
+
+	t.Run("should keep the paused game past the resume window", func(t *testing.T) {
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+
+		assert.Equal(t, nightfury.Paused, actual.GameStatuses["snakes"].Status)
+	})
+
+	t.Run("should resume the paused game reconnecting past the resume window", func(t *testing.T) {
+		gameConn = dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+		defer gameConn.Close()
+
+		resumed := readMessage(t, gameConn)
+		state := ResumeState{}
+		_ = resumed.DecodePayload(&state)
+		assert.Equal(t, gameResumed, resumed.Action)
+		assert.Equal(t, nightfury.Paused, state.Status.Status)
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		assert.Equal(t, nightfury.Paused, actual.GameStatuses["snakes"].Status)
+	})
+}
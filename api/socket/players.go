@@ -0,0 +1,61 @@
+package socket
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"time"
+)
+
+const registerClient = "register"
+
+// RegisterPlayer registers the player on the client, whose games can be started afterwards
+func RegisterPlayer(repo db.Repository, id string, registration nightfury.Registration, actor nightfury.Actor) (nightfury.Player, error) {
+	client, err := lockedClient(repo, id)
+	if err != nil {
+		return nightfury.Player{}, err
+	}
+	defer release()
+	return register(repo, client, registration, actor)
+}
+
+// ErasePlayer deletes the player, unregistering them from the client they registered on
+func ErasePlayer(repo db.Repository, player nightfury.Player) error {
+	if !acquire() {
+		return fmt.Errorf("server is shutting down")
+	}
+	defer release()
+	client, err := nightfury.NewClientFromRepoWithName(repo, player.Client)
+	if _, ok := err.(db.EntryNotFound); err != nil && !ok {
+		return err
+	}
+	if err == nil && client.Player == player.ID() {
+		client.Player = ""
+		if err := client.Save(repo); err != nil {
+			return err
+		}
+	}
+	return player.Delete(repo)
+}
+
+// register registers the player on the client and audits the registration, leaving out the name and email of the player
+func register(repository db.Repository, client nightfury.Client, registration nightfury.Registration, actor nightfury.Actor) (nightfury.Player, error) {
+	player, err := nightfury.NewPlayer(registration, client.Name, time.Now())
+	if err != nil {
+		return player, err
+	}
+	registered, err := client.Register(player)
+	if err != nil {
+		return player, err
+	}
+	if err := player.Save(repository); err != nil {
+		return player, err
+	}
+	if err := registered.Save(repository); err != nil {
+		logErr(player.Delete(repository))
+		return player, err
+	}
+	clientLogger(client).Infof("player %v registered on client '%v'", player.ID(), client.Name)
+	auditChange(repository, actor, registerClient, "player", player.ID(), client.Name, nil, player.Redacted())
+	return player, nil
+}
@@ -0,0 +1,57 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegistration(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	nightfury.RequireRegistration(true)
+	defer nightfury.RequireRegistration(false)
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+
+	clientConn := connectSocket(t, server, "/ws/v1/clients/booth-9")
+	defer clientConn.Close()
+	gameConn := connectSocket(t, server, "/ws/v1/clients/booth-9/games/snakes")
+	defer gameConn.Close()
+
+	t.Run("should refuse to start before a player registers", func(t *testing.T) {
+		start, _ := NewMessage(startClient, nil)
+		_ = clientConn.WriteJSON(start)
+
+		reply := readMessage(t, clientConn)
+
+		assert.Equal(t, messageError, reply.Action)
+		assert.JSONEq(t, `{"code": "unregistered", "message": "a player has to register on client booth-9 before starting"}`, string(reply.Payload))
+	})
+
+	t.Run("should refuse a registration without consent", func(t *testing.T) {
+		register, _ := NewMessage(registerClient, nightfury.Registration{Name: "Ada", Email: "ada@example.com"})
+		_ = clientConn.WriteJSON(register)
+
+		reply := readMessage(t, clientConn)
+
+		assert.Equal(t, messageError, reply.Action)
+		assert.Contains(t, string(reply.Payload), `"code":"invalid-registration"`)
+	})
+
+	t.Run("should start once a player registers", func(t *testing.T) {
+		register, _ := NewMessage(registerClient, nightfury.Registration{Name: "Ada", Email: "ada@example.com", Consent: nightfury.Consent{Privacy: true}})
+		_ = clientConn.WriteJSON(register)
+		assert.Equal(t, messageAck, readMessage(t, clientConn).Action)
+
+		start, _ := NewMessage(startClient, nil)
+		_ = clientConn.WriteJSON(start)
+
+		assert.Equal(t, startClient, readMessage(t, gameConn).Action)
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "booth-9")
+		player, err := nightfury.NewPlayerFromRepoWithID(repository, client.Player)
+		assert.NoError(t, err)
+		assert.Equal(t, "booth-9", player.Client)
+	})
+}
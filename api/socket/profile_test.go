@@ -0,0 +1,45 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientProfileCapture(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/v1/clients/kiosk-1?device=tablet&version=1.4.0"
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"User-Agent": []string{"kiosk-shell/2.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	t.Run("should capture the device details when the client connects", func(t *testing.T) {
+		actual, _ := nightfury.NewClientFromRepoWithName(db.DefaultRepository(), "kiosk-1")
+
+		assert.Equal(t, "tablet", actual.Profile.DeviceType)
+		assert.Equal(t, "1.4.0", actual.Profile.Version)
+		assert.Equal(t, "kiosk-shell/2.0", actual.Profile.UserAgent)
+		assert.Equal(t, "127.0.0.1", actual.Profile.IPAddress)
+		assert.False(t, actual.Profile.ConnectedSince.IsZero())
+	})
+
+	t.Run("should keep the admin edited fields", func(t *testing.T) {
+		_, err := EditProfile(db.DefaultRepository(), "kiosk-1", nightfury.Profile{Location: "hall b", DeviceType: "touch-table", Tags: []string{"vip"}}, nightfury.Actor{})
+
+		actual, _ := nightfury.NewClientFromRepoWithName(db.DefaultRepository(), "kiosk-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "hall b", actual.Profile.Location)
+		assert.Equal(t, "touch-table", actual.Profile.DeviceType)
+		assert.Equal(t, "1.4.0", actual.Profile.Version)
+	})
+}
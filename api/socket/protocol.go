@@ -0,0 +1,61 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/nightfury"
+)
+
+const (
+	fromClient = "client"
+	fromGame   = "game"
+	fromServer = "server"
+
+	fromSpectator = "spectator"
+)
+
+// actionSpec describes an action of the protocol along with the payload it carries
+type actionSpec struct {
+	action  string
+	from    string
+	to      string
+	payload interface{}
+
+	optional bool
+}
+
+// protocol lists every action exchanged over the client and game sockets
+var protocol = []actionSpec{
+	{action: startClient, from: fromClient, to: fromServer},
+	{action: resetClient, from: fromClient, to: fromServer},
+	{action: chooseClient, from: fromClient, to: fromServer, payload: Choice{}},
+	{action: pauseClient, from: fromClient, to: fromServer},
+	{action: resumeClient, from: fromClient, to: fromServer},
+	{action: registerClient, from: fromClient, to: fromServer, payload: nightfury.Registration{}},
+
+	{action: gameStarted, from: fromGame, to: fromServer},
+	{action: gameProgress, from: fromGame, to: fromServer, payload: nightfury.Progress{}},
+	{action: gameCompleted, from: fromGame, to: fromServer, payload: nightfury.Progress{}, optional: true},
+	{action: gameFailed, from: fromGame, to: fromServer},
+
+	{action: gameStarted, from: fromServer, to: fromClient, payload: nightfury.Game{}},
+	{action: gameCompleted, from: fromServer, to: fromClient, payload: nightfury.Game{}},
+	{action: gameFailed, from: fromServer, to: fromClient, payload: nightfury.Game{}},
+	{action: gameProgress, from: fromServer, to: fromClient, payload: GameProgress{}},
+	{action: gamePaused, from: fromServer, to: fromClient, payload: nightfury.Game{}},
+	{action: raceStandings, from: fromServer, to: fromClient, payload: RaceStandings{}},
+	{action: offerChoice, from: fromServer, to: fromClient, payload: Choices{}},
+	{action: gameUnpaused, from: fromServer, to: fromClient, payload: nightfury.Game{}},
+	{action: rewarded, from: fromServer, to: fromClient, payload: nightfury.Reward{}},
+
+	{action: startClient, from: fromServer, to: fromGame, payload: nightfury.Game{}},
+	{action: gameResumed, from: fromServer, to: fromGame, payload: ResumeState{}},
+	{action: pauseClient, from: fromServer, to: fromGame, payload: nightfury.Game{}},
+
+	{action: messageAck, from: fromServer, to: fromClient},
+	{action: messageAck, from: fromServer, to: fromGame},
+	{action: messageError, from: fromServer, to: fromClient, payload: ErrorPayload{}},
+	{action: messageError, from: fromServer, to: fromGame, payload: ErrorPayload{}},
+	{action: serverShutdown, from: fromServer, to: fromClient},
+	{action: serverShutdown, from: fromServer, to: fromGame},
+
+	{action: messageError, from: fromServer, to: fromSpectator, payload: ErrorPayload{}},
+}
@@ -0,0 +1,173 @@
+package socket
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/log"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"time"
+)
+
+const raceStandings = "standings"
+
+// raceActor represents the server giving the clients back their games once the race completes
+var raceActor = nightfury.Actor{Type: nightfury.ServerActor, ID: "race"}
+
+// RaceStandings represents the standings of a race broadcast to its clients
+type RaceStandings struct {
+	Race      string               `json:"race"`
+	Status    nightfury.Status     `json:"status"`
+	Winner    string               `json:"winner,omitempty"`
+	Standings []nightfury.Standing `json:"standings"`
+}
+
+// StartRace starts the games of the race on all of its clients at the same moment, the games the clients
+// were assigned are kept along with the race to be given back once it completes
+func StartRace(repo db.Repository, name string, actor nightfury.Actor) (nightfury.Race, error) {
+	if !acquire() {
+		return nightfury.Race{}, fmt.Errorf("server is shutting down")
+	}
+	defer release()
+	race, err := nightfury.NewRaceFromRepoWithName(repo, name)
+	if err != nil {
+		return race, err
+	}
+	race, err = race.Start(time.Now())
+	if err != nil {
+		return race, err
+	}
+
+	games := make([]nightfury.Game, 0, len(race.Games))
+	for _, name := range race.Games {
+		game, err := nightfury.NewGameFromRepoWithName(repo, name)
+		if err != nil {
+			return race, err
+		}
+		games = append(games, game)
+	}
+
+	clients := make([]nightfury.Client, 0, len(race.Clients))
+	befores := map[string]nightfury.GameStatuses{}
+	race.Assigned = map[string][]string{}
+	for _, id := range race.Clients {
+		client, err := nightfury.NewClientFromRepoWithName(repo, id)
+		if _, ok := err.(db.EntryNotFound); ok {
+			return race, fmt.Errorf("client %v is not connected", id)
+		} else if err != nil {
+			return race, err
+		}
+		if !client.Available {
+			return race, fmt.Errorf("client %v is not connected", id)
+		}
+		if client.Team != "" {
+			return race, fmt.Errorf("client %v is a member of team %v and cannot race", id, client.Team)
+		}
+		befores[client.Name] = client.GameStatuses
+		race.Assigned[client.Name] = client.Games
+		client, err = client.Assign(games...)
+		if err != nil {
+			return race, err
+		}
+		// every client is checked before any starts, so that the race does not start on a part of them
+		if _, err := client.Startable(repo, connectedGamesOf(client)...); err != nil {
+			return race, fmt.Errorf("client %v cannot race, %v", id, err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := race.Save(repo); err != nil {
+		return race, err
+	}
+	for _, client := range clients {
+		client.Race = race.Name
+		firstGame, err := client.Start(repo, connectedGamesOf(client)...)
+		recordChanges(repo, client, befores[client.Name], actor, startClient)
+		if err != nil {
+			clientLogger(client).Errorf("cannot start race %v on client %v. Error: %v", race.Name, client.Name, err)
+			continue
+		}
+		messageGameToStart(client, firstGame)
+	}
+	log.Infof("race '%v' has started", race.Name)
+	broadcastStandings(race)
+	return race, nil
+}
+
+// recordRaceResult updates the standings of the race the client is playing, if any
+func recordRaceResult(repository db.Repository, client nightfury.Client, game nightfury.Game, failed bool) {
+	if client.Race == "" {
+		return
+	}
+	race, err := nightfury.NewRaceFromRepoWithName(repository, client.Race)
+	if err != nil {
+		logErr(err)
+		return
+	}
+	if race.Status != nightfury.InProgress {
+		return
+	}
+	race, err = race.Record(client.Name, game.Name, failed, time.Now())
+	if err != nil {
+		logErr(err)
+		return
+	}
+	if err := race.Save(repository); err != nil {
+		logErr(err)
+		return
+	}
+	if race.Status == nightfury.Completed {
+		log.Infof("race '%v' has completed, won by '%v'", race.Name, race.Winner)
+	}
+	broadcastStandings(race)
+	if race.Status == nightfury.Completed {
+		restoreGames(repository, race)
+	}
+}
+
+// restoreGames gives the clients of the completed race back the games they were assigned before it,
+// the clients whose games were not configured get back the games connected on them
+func restoreGames(repository db.Repository, race nightfury.Race) {
+	for _, name := range race.Clients {
+		client, err := nightfury.NewClientFromRepoWithName(repository, name)
+		if err != nil {
+			logErr(err)
+			continue
+		}
+		names := race.Assigned[name]
+		if len(names) == 0 {
+			names = connectedGamesOf(client)
+		}
+		games := make([]nightfury.Game, 0, len(names))
+		for _, name := range names {
+			game, err := nightfury.NewGameFromRepoWithName(repository, name)
+			if err != nil {
+				logErr(err)
+				continue
+			}
+			games = append(games, game)
+		}
+		before := client.GameStatuses.Copy()
+		restored, err := client.Assign(games...)
+		if err != nil {
+			logErr(err)
+			continue
+		}
+		if len(race.Assigned[name]) == 0 {
+			restored.Games = nil
+		}
+		restored.Race = ""
+		if err := restored.Save(repository); err != nil {
+			logErr(err)
+			continue
+		}
+		clientLogger(client).Infof("client '%v' has been given back its games after race '%v'", client.Name, race.Name)
+		recordChanges(repository, restored, before, raceActor, assignGames)
+	}
+}
+
+func broadcastStandings(race nightfury.Race) {
+	standings := RaceStandings{Race: race.Name, Status: race.Status, Winner: race.Winner, Standings: race.Standings}
+	for _, name := range race.Clients {
+		broadcastMessageToClient(nightfury.Client{Name: name}, raceStandings, standings)
+	}
+}
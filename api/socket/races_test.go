@@ -0,0 +1,138 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRace(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+	_ = nightfury.NewRace("final", []string{"kiosk-1", "kiosk-2"}, []string{"snakes"}).Save(repository)
+
+	firstClientConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer firstClientConn.Close()
+	secondClientConn := dialSocket(t, server, "/ws/v1/clients/kiosk-2")
+	defer secondClientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+	firstGameConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+	defer firstGameConn.Close()
+	secondGameConn := dialSocket(t, server, "/ws/v1/clients/kiosk-2/games/snakes")
+	defer secondGameConn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	t.Run("should start race on every client", func(t *testing.T) {
+		race, err := StartRace(db.DefaultRepository(), "final", nightfury.Actor{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, nightfury.InProgress, race.Status)
+		assert.Equal(t, startClient, readMessage(t, firstGameConn).Action)
+		assert.Equal(t, startClient, readMessage(t, secondGameConn).Action)
+		assert.Equal(t, raceStandings, readMessage(t, firstClientConn).Action)
+		assert.Equal(t, raceStandings, readMessage(t, secondClientConn).Action)
+	})
+
+	t.Run("should not start race twice", func(t *testing.T) {
+		_, err := StartRace(db.DefaultRepository(), "final", nightfury.Actor{})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("should broadcast standings to every client after a game completes", func(t *testing.T) {
+		message, _ := NewMessage(gameCompleted, nil)
+		_ = secondGameConn.WriteJSON(message)
+
+		assert.Equal(t, gameCompleted, readMessage(t, secondClientConn).Action)
+		for _, actual := range []Message{readMessage(t, firstClientConn), readMessage(t, secondClientConn)} {
+			standings := RaceStandings{}
+			_ = actual.DecodePayload(&standings)
+			assert.Equal(t, raceStandings, actual.Action)
+			assert.Equal(t, "kiosk-2", standings.Winner)
+			assert.Equal(t, "kiosk-2", standings.Standings[0].Client)
+		}
+	})
+
+	t.Run("should complete race once every client has finished", func(t *testing.T) {
+		message, _ := NewMessage(gameFailed, nil)
+		_ = firstGameConn.WriteJSON(message)
+		time.Sleep(50 * time.Millisecond)
+
+		race, _ := nightfury.NewRaceFromRepoWithName(repository, "final")
+
+		assert.Equal(t, nightfury.Completed, race.Status)
+		assert.Equal(t, "kiosk-2", race.Winner)
+	})
+}
+
+func TestRaceRestoresGames(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	snakes := nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}
+	ludo := nightfury.Game{Name: "ludo", Instruction: "instruction", Type: "web"}
+	_ = snakes.Save(repository)
+	_ = ludo.Save(repository)
+	_ = nightfury.NewRace("heat", []string{"kiosk-7", "kiosk-8"}, []string{"snakes"}).Save(repository)
+	_, _ = AssignGames(repository, "kiosk-7", []nightfury.Game{ludo, snakes}, nightfury.Actor{})
+
+	var gameConns []*websocket.Conn
+	for _, path := range []string{"/ws/v1/clients/kiosk-7", "/ws/v1/clients/kiosk-8",
+		"/ws/v1/clients/kiosk-7/games/snakes", "/ws/v1/clients/kiosk-8/games/snakes"} {
+		conn := connectSocket(t, server, path)
+		defer conn.Close()
+		gameConns = append(gameConns, conn)
+	}
+	_, err := StartRace(repository, "heat", nightfury.Actor{})
+	assert.NoError(t, err)
+
+	completed, _ := NewMessage(gameCompleted, nil)
+	_ = gameConns[2].WriteJSON(completed)
+	failed, _ := NewMessage(gameFailed, nil)
+	_ = gameConns[3].WriteJSON(failed)
+	time.Sleep(50 * time.Millisecond)
+
+	race, _ := nightfury.NewRaceFromRepoWithName(repository, "heat")
+	assert.Equal(t, nightfury.Completed, race.Status)
+	assigned, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-7")
+	assert.Equal(t, []string{"ludo", "snakes"}, assigned.Games)
+	assert.Equal(t, nightfury.Ready, assigned.Status())
+	assert.Len(t, assigned.GameStatuses, 2)
+	assert.Empty(t, assigned.Race)
+	unconfigured, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-8")
+	assert.Empty(t, unconfigured.Games)
+	assert.Equal(t, nightfury.GameStatuses{"snakes": {Name: "snakes", Status: nightfury.Ready}}, unconfigured.GameStatuses)
+	assert.Empty(t, unconfigured.Race)
+}
+
+func TestRaceNotStartable(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "seeker", Instruction: "instruction", Type: "web", Prerequisites: []string{"smile"}}.Save(repository)
+	_ = nightfury.Game{Name: "smile", Instruction: "instruction", Type: "web", Prerequisites: []string{"seeker"}}.Save(repository)
+	_ = nightfury.NewRace("semi", []string{"kiosk-5", "kiosk-6"}, []string{"seeker", "smile"}).Save(repository)
+
+	for _, path := range []string{"/ws/v1/clients/kiosk-5", "/ws/v1/clients/kiosk-6",
+		"/ws/v1/clients/kiosk-5/games/seeker", "/ws/v1/clients/kiosk-5/games/smile",
+		"/ws/v1/clients/kiosk-6/games/seeker", "/ws/v1/clients/kiosk-6/games/smile"} {
+		conn := connectSocket(t, server, path)
+		defer conn.Close()
+	}
+
+	_, err := StartRace(db.DefaultRepository(), "semi", nightfury.Actor{})
+
+	assert.EqualError(t, err, "client kiosk-5 cannot race, cannot find any ready game")
+	race, _ := nightfury.NewRaceFromRepoWithName(repository, "semi")
+	assert.NotEqual(t, nightfury.InProgress, race.Status)
+	for _, name := range []string{"kiosk-5", "kiosk-6"} {
+		client, _ := nightfury.NewClientFromRepoWithName(repository, name)
+		assert.Empty(t, client.Race)
+		assert.False(t, client.GameStatuses.IsAnyGameInProgress())
+	}
+}
@@ -0,0 +1,119 @@
+package socket
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"gopkg.in/olahol/melody.v1"
+	"time"
+)
+
+const gameResumed = "resume"
+
+var resumeWindow = 30 * time.Second
+var pendingRemovals = map[string]*time.Timer{}
+
+// ResumeState represents the state sent to a game which connects to a client already keeping its status
+type ResumeState struct {
+	Game   nightfury.Game       `json:"game"`
+	Status nightfury.GameStatus `json:"status"`
+}
+
+// ID returns the identifiable name for resume state
+func (r ResumeState) ID() string {
+	return r.Game.Name
+}
+
+// ConfigureResumeWindow sets how long a disconnected game keeps its status
+func ConfigureResumeWindow(window time.Duration) {
+	lock.Lock()
+	defer lock.Unlock()
+	resumeWindow = window
+}
+
+func removalKey(client nightfury.Client, game nightfury.Game) string {
+	return fmt.Sprintf("%v/%v", client.Name, game.Name)
+}
+
+// scheduleRemoval removes the game from the client once the resume window has elapsed, unless the game
+// reconnects before that or is connected on a team member. Paused and assigned games wait for their game to reconnect
+func scheduleRemoval(client nightfury.Client, game nightfury.Game) {
+	key := removalKey(client, game)
+	if timer, ok := pendingRemovals[key]; ok {
+		timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(resumeWindow, func() {
+		if !acquire() {
+			return
+		}
+		defer release()
+		// a callback already waiting on the lock when its removal was cancelled or rescheduled
+		// must not take the place of the pending one
+		if pendingRemovals[key] != timer {
+			return
+		}
+		delete(pendingRemovals, key)
+
+		repository := db.DefaultRepository()
+		current, err := nightfury.NewClientFromRepoWithName(repository, client.Name)
+		if err != nil {
+			logErr(err)
+			return
+		}
+		if current.IsConfigured() && current.Plays(game.Name) {
+			gameLogger(client, game).Infof("game '%v' is assigned to client '%v', keeping its status until it reconnects", game.Name, client.Name)
+			return
+		}
+		if includes(connectedGamesOf(current), game.Name) {
+			gameLogger(client, game).Infof("game '%v' is connected on a team member of client '%v', keeping its status", game.Name, client.Name)
+			return
+		}
+		if current.GameStatuses[game.Name].Status == nightfury.Paused {
+			gameLogger(client, game).Infof("game '%v' of client '%v' is paused, keeping its status until it reconnects", game.Name, client.Name)
+			return
+		}
+		before := current.GameStatuses.Copy()
+		current.Remove(game)
+		err = current.Save(repository)
+		logErr(err)
+		recordChanges(repository, current, before, reaperActor, removeGame)
+		gameLogger(client, game).Infof("game '%v' of client '%v' removed after resume window", game.Name, client.Name)
+	})
+	pendingRemovals[key] = timer
+}
+
+// cancelRemoval returns true if the game had a pending removal, i.e. it reconnected within the resume window
+func cancelRemoval(client nightfury.Client, game nightfury.Game) bool {
+	key := removalKey(client, game)
+	timer, ok := pendingRemovals[key]
+	if !ok {
+		return false
+	}
+	timer.Stop()
+	delete(pendingRemovals, key)
+	return true
+}
+
+func resumeGame(session *melody.Session, client nightfury.Client, game nightfury.Game) {
+	status, ok := client.GameStatuses[game.Name]
+	if !ok {
+		status = nightfury.GameStatus{Name: game.Name, Status: nightfury.Ready}
+	}
+	message, err := NewMessage(gameResumed, ResumeState{Game: game, Status: status})
+	if err != nil {
+		logErr(err)
+		return
+	}
+	writeMessage(session, message)
+	sessionLogger(session).Infof("game '%v' of client '%v' resumed as %v", game.Name, client.Name, status.Status)
+}
+
+func includes(names []string, name string) bool {
+	for _, item := range names {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
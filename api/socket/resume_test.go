@@ -0,0 +1,89 @@
+package socket
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGameResume(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	defer ConfigureResumeWindow(resumeWindow)
+	repository := db.DefaultRepository()
+
+	clientConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer clientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	t.Run("should resume game reconnecting within resume window", func(t *testing.T) {
+		ConfigureResumeWindow(time.Second)
+		gameConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+		time.Sleep(50 * time.Millisecond)
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		client.GameStatuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.InProgress}
+		_ = client.Save(repository)
+
+		_ = gameConn.Close()
+		time.Sleep(50 * time.Millisecond)
+		gameConn = dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+		defer gameConn.Close()
+
+		_ = gameConn.SetReadDeadline(time.Now().Add(time.Second))
+		_, data, err := gameConn.ReadMessage()
+		assert.NoError(t, err)
+		message := struct {
+			Action  string
+			Payload ResumeState
+		}{}
+		_ = json.Unmarshal(data, &message)
+		assert.Equal(t, gameResumed, message.Action)
+		assert.Equal(t, nightfury.InProgress, message.Payload.Status.Status)
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		assert.Equal(t, nightfury.InProgress, actual.GameStatuses["snakes"].Status)
+	})
+
+	t.Run("should remove game not reconnecting within resume window", func(t *testing.T) {
+		ConfigureResumeWindow(50 * time.Millisecond)
+		gameConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/smile")
+		time.Sleep(50 * time.Millisecond)
+
+		_ = gameConn.Close()
+		time.Sleep(200 * time.Millisecond)
+
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		_, ok := actual.GameStatuses["smile"]
+		assert.False(t, ok)
+	})
+}
+
+func TestScheduleRemoval(t *testing.T) {
+	_, teardown := setupSocketTestContext(t)
+	defer teardown()
+	defer ConfigureResumeWindow(resumeWindow)
+	repository := db.DefaultRepository()
+	client := nightfury.NewClient("booth-10", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.InProgress})
+	_ = client.Save(repository)
+	game := nightfury.Game{Name: "snakes"}
+
+	t.Run("should not remove the game when a stale removal runs after it was rescheduled", func(t *testing.T) {
+		ConfigureResumeWindow(time.Millisecond)
+		lock.Lock()
+		scheduleRemoval(client, game)
+		time.Sleep(20 * time.Millisecond)
+		resumeWindow = time.Minute
+		scheduleRemoval(client, game)
+		lock.Unlock()
+		time.Sleep(20 * time.Millisecond)
+
+		lock.Lock()
+		pending := cancelRemoval(client, game)
+		lock.Unlock()
+		assert.True(t, pending)
+		actual, _ := nightfury.NewClientFromRepoWithName(repository, "booth-10")
+		assert.Equal(t, nightfury.InProgress, actual.GameStatuses["snakes"].Status)
+	})
+}
@@ -0,0 +1,27 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"time"
+)
+
+const rewarded = "reward"
+
+// allocateReward allocates a prize to the client which completed its run and sends the redemption code
+// to its client socket, nil if no prize is left for the run
+func allocateReward(repository db.Repository, client nightfury.Client, history nightfury.History, at time.Time) *nightfury.Reward {
+	run := nightfury.NewRun(client, history, at)
+	reward, ok, err := nightfury.AllocateReward(repository, client, run, at)
+	if err != nil {
+		clientLogger(client).Errorf("cannot allocate reward to client '%v'. Error: %v", client.Name, err)
+		return nil
+	}
+	if !ok {
+		clientLogger(client).Infof("no prize left for the run of client '%v' completed in %v", client.Name, run.Duration)
+		return nil
+	}
+	clientLogger(client).Infof("prize '%v' allocated to client '%v' with code %v", reward.Prize, client.Name, reward.Code)
+	broadcastMessageToClient(client, rewarded, reward)
+	return &reward
+}
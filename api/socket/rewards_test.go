@@ -0,0 +1,93 @@
+package socket
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRewardOnCompletion(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+	_ = nightfury.Prize{Name: "hoodie", Quantity: 1, Rules: nightfury.Eligibility{Within: "5m"}}.Save(repository)
+
+	clientConn := dialSocket(t, server, "/ws/v1/clients/booth-8")
+	defer clientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+	gameConn := dialSocket(t, server, "/ws/v1/clients/booth-8/games/snakes")
+	defer gameConn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	start, _ := NewMessage(startClient, nil)
+	_ = clientConn.WriteJSON(start)
+	assert.Equal(t, startClient, readMessage(t, gameConn).Action)
+	completed, _ := NewMessage(gameCompleted, nil)
+	_ = gameConn.WriteJSON(completed)
+	assert.Equal(t, messageAck, readMessage(t, gameConn).Action)
+
+	var reward nightfury.Reward
+	for i := 0; i < 5 && reward.Code == ""; i++ {
+		message := readMessage(t, clientConn)
+		if message.Action == rewarded {
+			_ = json.Unmarshal(message.Payload, &reward)
+		}
+	}
+
+	assert.Equal(t, "hoodie", reward.Prize)
+	assert.Equal(t, "booth-8", reward.Client)
+	stored, _ := nightfury.NewRewardFromRepoWithCode(repository, reward.Code)
+	assert.Equal(t, reward.Code, stored.Code)
+	prize, _ := nightfury.NewPrizeFromRepoWithName(repository, "hoodie")
+	assert.Equal(t, 0, prize.Quantity)
+}
+
+func TestRewardOnTeamMemberCompletion(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+	_ = nightfury.Game{Name: "ludo", Instruction: "instruction", Type: "web"}.Save(repository)
+	_ = nightfury.Prize{Name: "cap", Quantity: 1, Rules: nightfury.Eligibility{Within: "10ms"}}.Save(repository)
+
+	firstClientConn := connectSocket(t, server, "/ws/v1/clients/booth-9")
+	defer firstClientConn.Close()
+	secondClientConn := connectSocket(t, server, "/ws/v1/clients/booth-10")
+	defer secondClientConn.Close()
+	ludoConn := connectSocket(t, server, "/ws/v1/clients/booth-9/games/ludo")
+	defer ludoConn.Close()
+	snakesConn := connectSocket(t, server, "/ws/v1/clients/booth-10/games/snakes")
+	defer snakesConn.Close()
+	assert.NoError(t, nightfury.NewTeam("green", "booth-9", "booth-10").Form(repository))
+
+	start, _ := NewMessage(startClient, nil)
+	_ = firstClientConn.WriteJSON(start)
+	assert.Equal(t, startClient, readMessage(t, ludoConn).Action)
+	completed, _ := NewMessage(gameCompleted, nil)
+	_ = ludoConn.WriteJSON(completed)
+	assert.Equal(t, messageAck, readMessage(t, ludoConn).Action)
+	assert.Equal(t, startClient, readMessage(t, snakesConn).Action)
+	time.Sleep(30 * time.Millisecond)
+	_ = snakesConn.WriteJSON(completed)
+	assert.Equal(t, messageAck, readMessage(t, snakesConn).Action)
+
+	t.Run("should share the start of the run with the member completing it", func(t *testing.T) {
+		first, _ := nightfury.NewClientFromRepoWithName(repository, "booth-9")
+		second, _ := nightfury.NewClientFromRepoWithName(repository, "booth-10")
+		history, _ := nightfury.NewHistoryFromRepoWithName(repository, "booth-10")
+
+		assert.False(t, second.StartedAt.IsZero())
+		assert.True(t, first.StartedAt.Equal(second.StartedAt))
+		assert.True(t, nightfury.NewRun(second, history, time.Now()).Duration >= 30*time.Millisecond)
+	})
+
+	t.Run("should not reward the run completed slower than the prize rules", func(t *testing.T) {
+		prize, _ := nightfury.NewPrizeFromRepoWithName(repository, "cap")
+
+		assert.Equal(t, 1, prize.Quantity)
+	})
+}
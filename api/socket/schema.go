@@ -0,0 +1,110 @@
+package socket
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// Schema returns the JSON Schema of the socket protocol, generated from the message and payload types
+func Schema() map[string]interface{} {
+	definitions := map[string]interface{}{}
+	envelope := typeSchema(reflect.TypeOf(Message{}), definitions)
+
+	var messages []interface{}
+	for _, spec := range protocol {
+		properties := map[string]interface{}{
+			"action": map[string]interface{}{"const": spec.action},
+		}
+		required := []string{"action"}
+		if spec.payload != nil {
+			properties["payload"] = typeSchema(reflect.TypeOf(spec.payload), definitions)
+			if !spec.optional {
+				required = append(required, "payload")
+			}
+		}
+		messages = append(messages, map[string]interface{}{
+			"description": fmt.Sprintf("'%v' sent by %v to %v", spec.action, spec.from, spec.to),
+			"allOf": []interface{}{
+				envelope,
+				map[string]interface{}{"properties": properties, "required": required},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       fmt.Sprintf("nightfury socket protocol v%v", ProtocolVersion),
+		"definitions": definitions,
+		"anyOf":       messages,
+	}
+}
+
+func typeSchema(t reflect.Type, definitions map[string]interface{}) interface{} {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem(), definitions)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), definitions)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), definitions)}
+	case reflect.Struct:
+		if _, ok := definitions[t.Name()]; !ok {
+			definitions[t.Name()] = nil
+			definitions[t.Name()] = structSchema(t, definitions)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func structSchema(t reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, options := field.Name, ""
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			parts := strings.SplitN(tag, ",", 2)
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			if len(parts) > 1 {
+				options = parts[1]
+			}
+		}
+		properties[name] = typeSchema(field.Type, definitions)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+}
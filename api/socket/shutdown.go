@@ -0,0 +1,84 @@
+package socket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/olahol/melody.v1"
+	"sync/atomic"
+	"time"
+)
+
+const serverShutdown = "shutdown"
+
+// shuttingDown and resolvedTeams are guarded by the socket lock
+var shuttingDown bool
+var resolvedTeams = map[string]map[string]bool{}
+var shutdownMessage atomic.Value
+var pendingShutdownWrites int64
+
+// acquire takes the socket lock, returns false without holding the lock
+// once the server has started shutting down
+func acquire() bool {
+	lock.Lock()
+	if shuttingDown {
+		lock.Unlock()
+		return false
+	}
+	return true
+}
+
+func release() {
+	resolvedTeams = map[string]map[string]bool{}
+	lock.Unlock()
+}
+
+// messageSent counts down the shutdown messages written, since every session writes
+// its queue in order, all the messages queued before shutdown are flushed by then
+func messageSent(_ *melody.Session, data []byte) {
+	if expected, ok := shutdownMessage.Load().([]byte); ok && bytes.Equal(data, expected) {
+		atomic.AddInt64(&pendingShutdownWrites, -1)
+	}
+}
+
+// Shutdown notifies every connected client and game about the shutdown, waits up to timeout
+// for the queued messages to be written and closes both engines.
+// No message is processed or persisted once Shutdown has been called
+func Shutdown(timeout time.Duration) error {
+	message, err := NewMessage(serverShutdown, nil)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	shutdownMessage.Store(data)
+
+	lock.Lock()
+	shuttingDown = true
+	lock.Unlock()
+	for _, engine := range engines() {
+		if !engine.IsClosed() {
+			atomic.AddInt64(&pendingShutdownWrites, int64(engine.Len()))
+			logErr(engine.Broadcast(data))
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for atomic.LoadInt64(&pendingShutdownWrites) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, engine := range engines() {
+		if !engine.IsClosed() {
+			if err := engine.CloseWithMsg(melody.FormatCloseMessage(1001, serverShutdown)); err != nil {
+				return err
+			}
+		}
+	}
+	if pending := atomic.LoadInt64(&pendingShutdownWrites); pending > 0 {
+		return fmt.Errorf("%v sockets were closed before receiving the shutdown message", pending)
+	}
+	return nil
+}
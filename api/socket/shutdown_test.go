@@ -0,0 +1,155 @@
+package socket
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/olahol/melody.v1"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func setupSocketTestContext(t *testing.T) (*httptest.Server, func()) {
+	dir, _ := ioutil.TempDir("", "nightfury")
+	if err := db.Initialize(path.Join(dir, "db")); err != nil {
+		t.Fatal(err)
+	}
+	originalClientEngine, originalGameEngine, originalSpectatorEngine := clientEngine, gameEngine, spectatorEngine
+	clientEngine, gameEngine, spectatorEngine = melody.New(), melody.New(), melody.New()
+	BindSocket()
+	connectedSockets = make(chan string, 256)
+	clientEngine.HandleConnect(notifyConnected(clientConnected))
+	gameEngine.HandleConnect(notifyConnected(gameConnected))
+
+	lock.Lock()
+	shuttingDown = false
+	lock.Unlock()
+
+	// handlers tracks the socket handlers, the disconnect handlers of a test have to return
+	// before its db is closed, or they would run against the db of the next test
+	handlers := &sync.WaitGroup{}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		handlers.Add(1)
+		defer handlers.Done()
+		c.Next()
+	})
+	router.GET("/ws/v1/clients/:id", HandleClients)
+	router.GET("/ws/v1/clients/:id/games/:name", HandleGames)
+	router.GET("/ws/v1/clients/:id/spectators", HandleSpectators)
+	server := httptest.NewServer(router)
+
+	return server, func() {
+		for _, engine := range engines() {
+			_ = engine.Close()
+		}
+		waitFor(t, handlers)
+		server.Close()
+		lock.Lock()
+		clientEngine, gameEngine, spectatorEngine = originalClientEngine, originalGameEngine, originalSpectatorEngine
+		for key, timer := range pendingRemovals {
+			timer.Stop()
+			delete(pendingRemovals, key)
+		}
+		lock.Unlock()
+		connectionsLock.Lock()
+		connections = map[string]map[string]int{}
+		trackedSessions = map[*melody.Session]bool{}
+		connectionsLock.Unlock()
+		_ = db.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func waitFor(t *testing.T, handlers *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		handlers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("socket handlers did not return")
+	}
+}
+
+// connectedSockets receives the path of every socket whose connect handler has returned
+var connectedSockets chan string
+
+func notifyConnected(connectFn func(*melody.Session)) func(*melody.Session) {
+	return func(session *melody.Session) {
+		connectFn(session)
+		select {
+		case connectedSockets <- session.Request.URL.Path:
+		default:
+		}
+	}
+}
+
+// connectSocket dials the socket and waits for the server to have handled its connection
+func connectSocket(t *testing.T, server *httptest.Server, path string) *websocket.Conn {
+	conn := dialSocket(t, server, path)
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case connected := <-connectedSockets:
+			if connected == path {
+				return conn
+			}
+		case <-timeout:
+			_ = conn.Close()
+			t.Fatalf("socket %v was not connected", path)
+		}
+	}
+}
+
+func dialSocket(t *testing.T, server *httptest.Server, path string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + path
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) Message {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	message := Message{}
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = json.Unmarshal(data, &message)
+	return message
+}
+
+func TestShutdown(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+
+	clientConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer clientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+	gameConn := dialSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+	defer gameConn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	err := Shutdown(time.Second)
+
+	assert.NoError(t, err)
+	assert.Equal(t, serverShutdown, readMessage(t, clientConn).Action)
+	assert.Equal(t, serverShutdown, readMessage(t, gameConn).Action)
+	_, _, err = clientConn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+	assert.True(t, clientEngine.IsClosed())
+	assert.True(t, gameEngine.IsClosed())
+}
@@ -3,19 +3,30 @@ package socket
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/boothgames/nightfury/api/events"
 	"github.com/boothgames/nightfury/log"
+	"github.com/boothgames/nightfury/pkg/db"
 	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/boothgames/nightfury/pkg/token"
+	"github.com/gin-gonic/gin"
 	"gopkg.in/olahol/melody.v1"
+	"net/http"
 	"sync"
 )
 
 var gameEngine = melody.New()
 var clientEngine = melody.New()
+var spectatorEngine = melody.New()
 var lock = new(sync.Mutex)
 
 const (
 	socketClientID = "id"
 	socketGameID   = "name"
+	socketDevice   = "device"
+	socketRequest  = "request"
+	joinTokenParam = "token"
+
+	maxGameMessageSize = 4096
 )
 
 // BindSocket binds the necessary sockets related to games and clients
@@ -27,6 +38,21 @@ func BindSocket() {
 	gameEngine.HandleConnect(gameConnected)
 	gameEngine.HandleDisconnect(gameDisconnected)
 	gameEngine.HandleMessage(gameMessageReceived)
+
+	clientEngine.HandlePong(clientPongReceived)
+	gameEngine.HandlePong(gamePongReceived)
+
+	spectatorEngine.HandleMessage(spectatorMessageReceived)
+
+	gameEngine.Config.MaxMessageSize = maxGameMessageSize
+
+	for _, engine := range engines() {
+		engine.HandleSentMessage(messageSent)
+	}
+}
+
+func engines() []*melody.Melody {
+	return []*melody.Melody{clientEngine, gameEngine, spectatorEngine}
 }
 
 func gameName(session *melody.Session) (string, bool) {
@@ -49,42 +75,159 @@ func clientID(session *melody.Session) (string, bool) {
 	return "", false
 }
 
+// authorized aborts the request with unauthorized status if join tokens are enabled
+// and the request does not carry a token issued for the client id and game name
+func authorized(c *gin.Context, clientID, gameName string) bool {
+	signer, ok := token.DefaultSigner()
+	if !ok {
+		return true
+	}
+	if err := signer.Verify(c.Query(joinTokenParam), clientID, gameName); err != nil {
+		log.WithFields(log.Fields{log.ClientField: clientID, log.GameField: gameName, log.RequestIDField: c.GetString(log.RequestIDField)}).
+			Warnf("rejected socket of client '%v' and game '%v'. Error: %v", clientID, gameName, err)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
 func logErr(err error) {
 	if err != nil {
 		log.Error(err)
 	}
 }
 
-func broadcastMessageToClient(client nightfury.Client, message Message) {
-	broadcastMessage(clientEngine, message, func(session *melody.Session) bool {
+// clientLogger returns the logger carrying the name of the client
+func clientLogger(client nightfury.Client) log.Entry {
+	return log.WithFields(log.Fields{log.ClientField: client.Name})
+}
+
+// gameLogger returns the logger carrying the names of the client and the game
+func gameLogger(client nightfury.Client, game nightfury.Game) log.Entry {
+	return log.WithFields(log.Fields{log.ClientField: client.Name, log.GameField: game.Name})
+}
+
+// sessionLogger returns the logger carrying the client, the game and the id of the request which opened the session
+func sessionLogger(session *melody.Session) log.Entry {
+	fields := log.Fields{}
+	if id, ok := clientID(session); ok {
+		fields[log.ClientField] = id
+	}
+	if name, ok := gameName(session); ok {
+		fields[log.GameField] = name
+	}
+	if session != nil {
+		if requestID, ok := session.Keys[socketRequest].(string); ok && requestID != "" {
+			fields[log.RequestIDField] = requestID
+		}
+	}
+	return log.WithFields(fields)
+}
+
+// broadcastMessageToClient sends the message to the socket of the client and its team members,
+// their spectators and the server-sent event consumers
+func broadcastMessageToClient(client nightfury.Client, action string, payload interface{}) {
+	members := teamMembers(client)
+	data := broadcastMessage(action, payload, func(session *melody.Session) bool {
 		if name, ok := clientID(session); ok {
-			return name == client.Name
+			return members[name]
 		}
 		return false
-	})
+	}, clientEngine, spectatorEngine)
+	if data != nil {
+		for name := range members {
+			events.Publish(name, action, data)
+		}
+	}
 }
 
-func broadcastMessageToGame(client nightfury.Client, game nightfury.Game, message Message) {
-	broadcastMessage(gameEngine, message, func(session *melody.Session) bool {
+// broadcastMessageToGame sends the message to the game socket of the client, or of any team member
+// hosting the game
+func broadcastMessageToGame(client nightfury.Client, game nightfury.Game, action string, payload interface{}) {
+	members := teamMembers(client)
+	broadcastMessage(action, payload, func(session *melody.Session) bool {
 		if clientName, ok := clientID(session); ok {
 			if gameName, ok := gameName(session); ok {
-				return clientName == client.Name && gameName == game.Name
+				return members[clientName] && gameName == game.Name
 			}
 		}
 		return false
-	})
+	}, gameEngine)
 }
 
-func broadcastMessage(engine *melody.Melody, message Message, predicateFn func(session *melody.Session) bool) {
+// teamMembers returns the names of the client and its team members, a team is resolved once
+// until the socket lock is released
+func teamMembers(client nightfury.Client) map[string]bool {
+	if client.Team == "" {
+		return map[string]bool{client.Name: true}
+	}
+	if members, ok := resolvedTeams[client.Team]; ok {
+		return members
+	}
+	names, err := client.Members(db.DefaultRepository())
+	members := map[string]bool{}
+	for _, name := range names {
+		members[name] = true
+	}
+	if err != nil {
+		logErr(err)
+		return members
+	}
+	resolvedTeams[client.Team] = members
+	return members
+}
+
+// broadcastMessage returns the message sent, nil if it could not be encoded
+func broadcastMessage(action string, payload interface{}, predicateFn func(session *melody.Session) bool, targets ...*melody.Melody) []byte {
+	message, err := NewMessage(action, payload)
+	if err != nil {
+		log.Error(err)
+		return nil
+	}
 	data, err := json.Marshal(message)
 	if err != nil {
 		log.Error(err)
+		return nil
+	}
+	for _, engine := range targets {
+		if err := engine.BroadcastFilter(data, predicateFn); err != nil {
+			log.Error(err)
+		}
+	}
+	return data
+}
+
+// reply answers a message received on the session with an ack, or with an error if err is not nil.
+// Version 1 senders do not expect replies, their errors are only logged unless
+// the message could not be parsed at all
+func reply(session *melody.Session, message Message, err error) {
+	if err != nil {
+		sessionLogger(session).WithFields(log.Fields{log.ActionField: message.Action}).Error(err)
+	}
+	if message.Version < ProtocolVersion && errorCode(err) != malformedMessage {
 		return
 	}
 
-	err = engine.BroadcastFilter(data, predicateFn)
+	var response Message
+	var replyErr error
 	if err != nil {
-		log.Error(err)
+		response, replyErr = message.Reply(messageError, ErrorPayload{Code: errorCode(err), Message: err.Error()})
+	} else {
+		response, replyErr = message.Reply(messageAck, nil)
+	}
+	if replyErr != nil {
+		logErr(replyErr)
+		return
+	}
+	writeMessage(session, response)
+}
+
+func writeMessage(session *melody.Session, message Message) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		logErr(err)
 		return
 	}
+	err = session.Write(data)
+	logErr(err)
 }
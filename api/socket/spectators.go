@@ -0,0 +1,33 @@
+package socket
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/log"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/olahol/melody.v1"
+	"net/http"
+)
+
+// HandleSpectators handle read only socket connections watching a client,
+// they receive every message sent to the client socket
+func HandleSpectators(c *gin.Context) {
+	id := c.Param("id")
+	if !authorized(c, id, "") {
+		return
+	}
+	err := spectatorEngine.HandleRequestWithKeys(c.Writer, c.Request, map[string]interface{}{
+		socketClientID: id,
+		socketRequest:  c.GetString(log.RequestIDField),
+	})
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}
+}
+
+func spectatorMessageReceived(session *melody.Session, data []byte) {
+	message, err := ParseMessage(data)
+	if err == nil {
+		err = protocolError{code: "read-only", err: fmt.Errorf("spectator sockets are read-only")}
+	}
+	reply(session, message, err)
+}
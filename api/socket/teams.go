@@ -0,0 +1,46 @@
+package socket
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+)
+
+// TeamExists represents the error of forming a team whose name is taken
+type TeamExists string
+
+func (t TeamExists) Error() string {
+	return string(t)
+}
+
+// FormTeam forms the team out of its members and returns it along with the games they share,
+// returns error if a team with the same name exists
+func FormTeam(repo db.Repository, team nightfury.Team) (nightfury.Team, error) {
+	if !acquire() {
+		return team, fmt.Errorf("server is shutting down")
+	}
+	defer release()
+	_, err := nightfury.NewTeamFromRepoWithName(repo, team.Name)
+	if err == nil {
+		return team, TeamExists(fmt.Sprintf("team %v already exists", team.Name))
+	} else if _, ok := err.(db.EntryNotFound); !ok {
+		return team, err
+	}
+	if err := team.Form(repo); err != nil {
+		return team, err
+	}
+	return nightfury.NewTeamFromRepoWithName(repo, team.Name)
+}
+
+// DisbandTeam deletes the team and detaches its members, who keep a copy of the team progression
+func DisbandTeam(repo db.Repository, name string) (nightfury.Team, error) {
+	if !acquire() {
+		return nightfury.Team{}, fmt.Errorf("server is shutting down")
+	}
+	defer release()
+	team, err := nightfury.NewTeamFromRepoWithName(repo, name)
+	if err != nil {
+		return team, err
+	}
+	return team, team.Disband(repo)
+}
@@ -0,0 +1,135 @@
+package socket
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTeamProgression(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+	_ = nightfury.Game{Name: "ludo", Instruction: "instruction", Type: "web"}.Save(repository)
+
+	firstClientConn := connectSocket(t, server, "/ws/v1/clients/kiosk-1")
+	defer firstClientConn.Close()
+	secondClientConn := connectSocket(t, server, "/ws/v1/clients/kiosk-2")
+	defer secondClientConn.Close()
+	snakesConn := connectSocket(t, server, "/ws/v1/clients/kiosk-1/games/snakes")
+	defer snakesConn.Close()
+	ludoConn := connectSocket(t, server, "/ws/v1/clients/kiosk-2/games/ludo")
+	defer ludoConn.Close()
+
+	err := nightfury.NewTeam("red", "kiosk-1", "kiosk-2").Form(repository)
+	assert.NoError(t, err)
+
+	client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+	client.GameStatuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.InProgress}
+	_ = client.Save(repository)
+
+	t.Run("should broadcast completion to every member", func(t *testing.T) {
+		message, _ := NewMessage(gameCompleted, nil)
+		_ = snakesConn.WriteJSON(message)
+
+		assert.Equal(t, messageAck, readMessage(t, snakesConn).Action)
+		assert.Equal(t, gameCompleted, readMessage(t, firstClientConn).Action)
+		assert.Equal(t, gameCompleted, readMessage(t, secondClientConn).Action)
+	})
+
+	t.Run("should start next game on another member", func(t *testing.T) {
+		actual := readMessage(t, ludoConn)
+		game := nightfury.Game{}
+		_ = actual.DecodePayload(&game)
+
+		assert.Equal(t, startClient, actual.Action)
+		assert.Equal(t, "ludo", game.Name)
+	})
+
+	t.Run("should share progression with every member", func(t *testing.T) {
+		for _, name := range []string{"kiosk-1", "kiosk-2"} {
+			actual, _ := nightfury.NewClientFromRepoWithName(repository, name)
+
+			assert.Equal(t, nightfury.Completed, actual.GameStatuses["snakes"].Status)
+			assert.Equal(t, nightfury.InProgress, actual.GameStatuses["ludo"].Status)
+		}
+		team, _ := nightfury.NewTeamFromRepoWithName(repository, "red")
+		assert.Equal(t, nightfury.InProgress, team.GameStatuses["ludo"].Status)
+	})
+
+	t.Run("should record the shared progression in the history of every member", func(t *testing.T) {
+		for _, name := range []string{"kiosk-1", "kiosk-2"} {
+			actual, _ := nightfury.NewClientFromRepoWithName(repository, name)
+			history, _ := nightfury.NewHistoryFromRepoWithName(repository, name)
+
+			assert.Equal(t, actual.GameStatuses, history.Replay())
+		}
+	})
+}
+
+func TestTeamMembers(t *testing.T) {
+	_, teardown := setupSocketTestContext(t)
+	defer teardown()
+	repository := db.DefaultRepository()
+	team := nightfury.NewTeam("blue", "kiosk-3", "kiosk-4")
+	_ = team.Save(repository)
+	client := nightfury.Client{Name: "kiosk-3", Team: "blue"}
+
+	assert.True(t, acquire())
+	assert.Equal(t, map[string]bool{"kiosk-3": true, "kiosk-4": true}, teamMembers(client))
+	_ = team.Delete(repository)
+	assert.Equal(t, map[string]bool{"kiosk-3": true, "kiosk-4": true}, teamMembers(client), "should resolve the team once until released")
+	release()
+
+	assert.True(t, acquire())
+	assert.Equal(t, map[string]bool{"kiosk-3": true}, teamMembers(client))
+	release()
+}
+
+func TestTeamGameRemoval(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	defer ConfigureResumeWindow(resumeWindow)
+	ConfigureResumeWindow(50 * time.Millisecond)
+	repository := db.DefaultRepository()
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(repository)
+
+	firstClientConn := connectSocket(t, server, "/ws/v1/clients/kiosk-5")
+	defer firstClientConn.Close()
+	secondClientConn := connectSocket(t, server, "/ws/v1/clients/kiosk-6")
+	defer secondClientConn.Close()
+	firstSnakesConn := connectSocket(t, server, "/ws/v1/clients/kiosk-5/games/snakes")
+	secondSnakesConn := connectSocket(t, server, "/ws/v1/clients/kiosk-6/games/snakes")
+	defer secondSnakesConn.Close()
+	_, err := FormTeam(repository, nightfury.NewTeam("yellow", "kiosk-5", "kiosk-6"))
+	assert.NoError(t, err)
+
+	_ = firstSnakesConn.Close()
+	time.Sleep(200 * time.Millisecond)
+
+	t.Run("should keep the game still connected on another member", func(t *testing.T) {
+		team, _ := nightfury.NewTeamFromRepoWithName(repository, "yellow")
+
+		_, ok := team.GameStatuses["snakes"]
+		assert.True(t, ok)
+	})
+
+	t.Run("should refuse to form a team whose name is taken", func(t *testing.T) {
+		_, err := FormTeam(repository, nightfury.NewTeam("yellow", "kiosk-7"))
+
+		assert.Equal(t, TeamExists("team yellow already exists"), err)
+	})
+
+	t.Run("should disband the team", func(t *testing.T) {
+		_, err := DisbandTeam(repository, "yellow")
+
+		assert.NoError(t, err)
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-6")
+		assert.Equal(t, "", client.Team)
+		_, ok := client.GameStatuses["snakes"]
+		assert.True(t, ok)
+	})
+}
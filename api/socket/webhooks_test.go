@@ -0,0 +1,64 @@
+package socket
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/boothgames/nightfury/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifications(t *testing.T) {
+	server, teardown := setupSocketTestContext(t)
+	defer teardown()
+	dispatcher := webhook.NewDispatcher(time.Second, 1, time.Millisecond)
+	defer webhook.ReplaceDefaultDispatcherWith(dispatcher)()
+	var received []nightfury.Notification
+	receiverLock := new(sync.Mutex)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		notification := nightfury.Notification{}
+		_ = json.Unmarshal(body, &notification)
+		receiverLock.Lock()
+		received = append(received, notification)
+		receiverLock.Unlock()
+	}))
+	defer receiver.Close()
+	_ = nightfury.Webhook{Name: "prize-desk", URL: receiver.URL}.Save(db.GlobalRepository())
+	_ = nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"}.Save(db.DefaultRepository())
+
+	clientConn := dialSocket(t, server, "/ws/v1/clients/booth-7")
+	defer clientConn.Close()
+	time.Sleep(50 * time.Millisecond)
+	gameConn := dialSocket(t, server, "/ws/v1/clients/booth-7/games/snakes")
+	defer gameConn.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	start, _ := NewMessage(startClient, nil)
+	_ = clientConn.WriteJSON(start)
+	assert.Equal(t, startClient, readMessage(t, gameConn).Action)
+	completed, _ := NewMessage(gameCompleted, nil)
+	_ = gameConn.WriteJSON(completed)
+	assert.Equal(t, messageAck, readMessage(t, gameConn).Action)
+	reset, _ := NewMessage(resetClient, nil)
+	_ = clientConn.WriteJSON(reset)
+	time.Sleep(50 * time.Millisecond)
+	dispatcher.Wait()
+
+	receiverLock.Lock()
+	defer receiverLock.Unlock()
+	if assert.Len(t, received, 2) {
+		assert.Equal(t, nightfury.ClientCompletedNotification, received[0].Event)
+		assert.Equal(t, nightfury.Completed, received[0].Status)
+		assert.Equal(t, nightfury.ClientResetNotification, received[1].Event)
+		assert.Equal(t, "booth-7", received[1].Client)
+	}
+	deliveries, _ := nightfury.NewDeliveriesFromRepo(db.GlobalRepository(), "prize-desk")
+	assert.Len(t, deliveries, 2)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"github.com/boothgames/nightfury/api/socket"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func listTeams(c *gin.Context) {
+	repository := scopedRepository(c)
+	teams, err := nightfury.NewTeamsFromRepo(repository)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, teams)
+}
+
+func createTeam(c *gin.Context) {
+	team := nightfury.Team{}
+	repository := scopedRepository(c)
+	err := c.ShouldBindJSON(&team)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	team, err = socket.FormTeam(repository, team)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "create", "team", team.ID(), nil, team)
+	c.JSON(http.StatusCreated, team)
+}
+
+func populateTeam(c *gin.Context) {
+	teamName := c.Param("id")
+	repository := scopedRepository(c)
+	team, err := nightfury.NewTeamFromRepoWithName(repository, teamName)
+	if err != nil {
+		if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Set("team", team)
+}
+
+func readTeam(c *gin.Context) {
+	team, _ := c.Get("team")
+	c.JSON(http.StatusOK, team)
+}
+
+func deleteTeam(c *gin.Context) {
+	team, _ := c.Get("team")
+	repository := scopedRepository(c)
+	disbanded, err := socket.DisbandTeam(repository, team.(nightfury.Team).Name)
+	if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+		return
+	} else if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "delete", "team", disbanded.ID(), disbanded, nil)
+	c.Status(http.StatusOK)
+}
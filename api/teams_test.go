@@ -0,0 +1,64 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestTeamAPI(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	repository := db.DefaultRepository()
+
+	_ = nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.Ready}).Save(repository)
+	_ = nightfury.NewClient("kiosk-2", true, nightfury.GameStatus{Name: "ludo", Status: nightfury.Ready}).Save(repository)
+
+	t.Run("create team", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/teams", nightfury.NewTeam("red", "kiosk-1", "kiosk-2"))
+
+		assert.Equal(t, http.StatusCreated, response.Code)
+		actual := nightfury.Team{}
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, []string{"kiosk-1", "kiosk-2"}, actual.Members)
+		assert.Len(t, actual.GameStatuses, 2)
+	})
+
+	t.Run("create team should fail when team exists", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/teams", nightfury.NewTeam("red", "kiosk-1"))
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, `{"error":"team red already exists"}`, response.Body.String())
+	})
+
+	t.Run("create team should fail when member belongs to another team", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/teams", nightfury.NewTeam("blue", "kiosk-1"))
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, `{"error":"client kiosk-1 is already a member of team red"}`, response.Body.String())
+	})
+
+	t.Run("read team", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/teams/red", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("delete team", func(t *testing.T) {
+		response := performRequest(router, "DELETE", "/v1/teams/red", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		client, _ := nightfury.NewClientFromRepoWithName(repository, "kiosk-1")
+		assert.Equal(t, "", client.Team)
+	})
+
+	t.Run("read team should fail when team does not exist", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/teams/red", nil)
+
+		assert.Equal(t, http.StatusNotFound, response.Code)
+		assert.Equal(t, `{"error":"team with name red doesn't exists"}`, response.Body.String())
+	})
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/boothgames/nightfury/pkg/token"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+)
+
+const provisioningKeyHeader = "X-Provisioning-Key"
+
+var provisioningKey string
+
+// SetProvisioningKey sets the key a provisioned kiosk presents to fetch its join tokens
+func SetProvisioningKey(key string) {
+	provisioningKey = key
+}
+
+type joinToken struct {
+	token.Token
+	URL string `json:"url"`
+}
+
+type joinTokens struct {
+	Client joinToken            `json:"client"`
+	Games  map[string]joinToken `json:"games"`
+}
+
+func requireProvisioningKey(c *gin.Context) {
+	if _, ok := token.DefaultSigner(); !ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "join tokens are not enabled"})
+		return
+	}
+	if provisioningKey == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader(provisioningKeyHeader)), []byte(provisioningKey)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid provisioning key"})
+		return
+	}
+}
+
+func issueTokens(c *gin.Context) {
+	clientID := c.Param("id")
+	signer, _ := token.DefaultSigner()
+	repository := db.DefaultRepository()
+	games, err := nightfury.NewGamesFromRepo(repository)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	clientToken, err := signer.Issue(clientID, "")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	result := joinTokens{
+		Client: joinToken{Token: clientToken, URL: socketURL(c, fmt.Sprintf("/ws/v1/clients/%v", clientID), clientToken)},
+		Games:  map[string]joinToken{},
+	}
+	for _, model := range games.(map[string]interface{}) {
+		game := model.(nightfury.Game)
+		gameToken, err := signer.Issue(clientID, game.Name)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		path := fmt.Sprintf("/ws/v1/clients/%v/games/%v", clientID, game.Name)
+		result.Games[game.Name] = joinToken{Token: gameToken, URL: socketURL(c, path, gameToken)}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func socketURL(c *gin.Context, path string, joinToken token.Token) string {
+	scheme := "ws"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "wss"
+	}
+	socket := url.URL{Scheme: scheme, Host: c.Request.Host, Path: path, RawQuery: url.Values{"token": {joinToken.Value}}.Encode()}
+	return socket.String()
+}
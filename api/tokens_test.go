@@ -0,0 +1,74 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/api"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/boothgames/nightfury/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJoinTokens(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+
+	signer := token.NewSigner([]byte("secret"), time.Minute)
+	restore := token.ReplaceDefaultSignerWith(&signer)
+	defer restore()
+	api.SetProvisioningKey("kiosk-key")
+	defer api.SetProvisioningKey("")
+
+	_ = performRequest(router, "POST", "/v1/games", nightfury.Game{Name: "snakes", Instruction: "instruction", Type: "web"})
+
+	t.Run("should reject request without provisioning key", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/clients/kiosk-1/tokens", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("should issue tokens for the client and its games", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/v1/clients/kiosk-1/tokens", nil)
+		request.Header.Set("X-Provisioning-Key", "kiosk-key")
+		response := httptest.NewRecorder()
+
+		router.ServeHTTP(response, request)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		actual := struct {
+			Client struct{ Token, URL string }
+			Games  map[string]struct{ Token, URL string }
+		}{}
+		assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &actual))
+		assert.NoError(t, signer.Verify(actual.Client.Token, "kiosk-1", ""))
+		assert.NoError(t, signer.Verify(actual.Games["snakes"].Token, "kiosk-1", "snakes"))
+		assert.True(t, strings.HasPrefix(actual.Games["snakes"].URL, "ws://"))
+	})
+
+	t.Run("should reject game socket without token", func(t *testing.T) {
+		response := performRequest(router, "GET", "/ws/v1/clients/kiosk-1/games/snakes", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("should reject game socket with token of another game", func(t *testing.T) {
+		issued, _ := signer.Issue("kiosk-1", "smile")
+
+		response := performRequest(router, "GET", "/ws/v1/clients/kiosk-1/games/snakes?token="+issued.Value, nil)
+
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+}
+
+func TestJoinTokensDisabled(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+
+	response := performRequest(router, "GET", "/v1/clients/kiosk-1/tokens", nil)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
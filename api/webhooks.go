@@ -0,0 +1,122 @@
+package api
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func listWebhooks(c *gin.Context) {
+	models, err := nightfury.NewWebhooksFromRepo(db.GlobalRepository())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	webhooks := nightfury.Webhooks{}
+	all, _ := models.(map[string]interface{})
+	for name, model := range all {
+		if webhook, ok := model.(nightfury.Webhook); ok {
+			webhooks[name] = webhook.Redacted()
+		}
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func createWebhook(c *gin.Context) {
+	webhook := nightfury.Webhook{}
+	repository := db.GlobalRepository()
+	err := c.ShouldBindJSON(&webhook)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = webhook.Validate()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := nightfury.NewWebhookFromRepoWithName(repository, webhook.Name); err == nil {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("webhook with name %v already exists", webhook.Name)})
+		return
+	}
+	err = webhook.Save(repository)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "create", "webhook", webhook.ID(), nil, webhook.Redacted())
+	c.JSON(http.StatusCreated, webhook.Redacted())
+}
+
+func populateWebhook(c *gin.Context) {
+	webhook, err := nightfury.NewWebhookFromRepoWithName(db.GlobalRepository(), c.Param("id"))
+	if err != nil {
+		if entryNotFoundErr, ok := err.(db.EntryNotFound); ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": entryNotFoundErr.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Set("webhook", webhook)
+}
+
+func readWebhook(c *gin.Context) {
+	webhook, _ := c.Get("webhook")
+	c.JSON(http.StatusOK, webhook.(nightfury.Webhook).Redacted())
+}
+
+// updateWebhook replaces the webhook, keeping its secret unless a new one is given
+func updateWebhook(c *gin.Context) {
+	webhook, _ := c.Get("webhook")
+	currentWebhook := webhook.(nightfury.Webhook)
+	webhookToBeUpdated := nightfury.Webhook{}
+	err := c.ShouldBindJSON(&webhookToBeUpdated)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if currentWebhook.Name != webhookToBeUpdated.Name {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Errorf("name cannot be different").Error()})
+		return
+	}
+	if webhookToBeUpdated.Secret == "" {
+		webhookToBeUpdated.Secret = currentWebhook.Secret
+	}
+	err = webhookToBeUpdated.Validate()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err = webhookToBeUpdated.Save(db.GlobalRepository())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "update", "webhook", webhookToBeUpdated.ID(), currentWebhook.Redacted(), webhookToBeUpdated.Redacted())
+	c.JSON(http.StatusOK, webhookToBeUpdated.Redacted())
+}
+
+func deleteWebhook(c *gin.Context) {
+	webhook, _ := c.Get("webhook")
+	webhookToBeDeleted := webhook.(nightfury.Webhook)
+	err := webhookToBeDeleted.Delete(db.GlobalRepository())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	audit(c, "delete", "webhook", webhookToBeDeleted.ID(), webhookToBeDeleted.Redacted(), nil)
+	c.Status(http.StatusOK)
+}
+
+func listDeliveries(c *gin.Context) {
+	webhook, _ := c.Get("webhook")
+	deliveries, err := nightfury.NewDeliveriesFromRepo(db.GlobalRepository(), webhook.(nightfury.Webhook).Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
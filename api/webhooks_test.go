@@ -0,0 +1,80 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestWebhooks(t *testing.T) {
+	router := setupTestContext()
+	defer teardownTestContext(t)
+	webhook := nightfury.Webhook{Name: "prize-desk", URL: "http://localhost:9000/hooks", Secret: "secret", Events: []string{nightfury.ClientCompletedNotification}}
+
+	t.Run("should create the webhook without returning its secret", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/webhooks", webhook)
+
+		actual := nightfury.Webhook{}
+		_ = json.Unmarshal(response.Body.Bytes(), &actual)
+		assert.Equal(t, http.StatusCreated, response.Code)
+		assert.Equal(t, webhook.Redacted(), actual)
+		stored, _ := nightfury.NewWebhookFromRepoWithName(db.GlobalRepository(), "prize-desk")
+		assert.Equal(t, "secret", stored.Secret)
+	})
+
+	t.Run("should refuse an existing webhook", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/webhooks", webhook)
+
+		assert.Equal(t, http.StatusConflict, response.Code)
+	})
+
+	t.Run("should refuse an invalid webhook", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/webhooks", nightfury.Webhook{Name: "chat-bot", URL: "chat"})
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("should refuse a webhook without secret", func(t *testing.T) {
+		response := performRequest(router, "POST", "/v1/webhooks", nightfury.Webhook{Name: "chat-bot", URL: "http://localhost:9000/chat"})
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, `{"error":"secret of webhook chat-bot cannot be empty"}`, response.Body.String())
+	})
+
+	t.Run("should list the webhooks", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/webhooks", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, `{"prize-desk":{"name":"prize-desk","url":"http://localhost:9000/hooks","events":["client.completed"]}}`, response.Body.String())
+	})
+
+	t.Run("should keep the secret when updating without one", func(t *testing.T) {
+		updated := nightfury.Webhook{Name: "prize-desk", URL: "http://localhost:9000/v2/hooks"}
+
+		response := performRequest(router, "PUT", "/v1/webhooks/prize-desk", updated)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		stored, _ := nightfury.NewWebhookFromRepoWithName(db.GlobalRepository(), "prize-desk")
+		assert.Equal(t, "secret", stored.Secret)
+		assert.Equal(t, "http://localhost:9000/v2/hooks", stored.URL)
+		assert.Empty(t, stored.Events)
+	})
+
+	t.Run("should list the deliveries of the webhook", func(t *testing.T) {
+		response := performRequest(router, "GET", "/v1/webhooks/prize-desk/deliveries", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "[]", response.Body.String())
+	})
+
+	t.Run("should delete the webhook", func(t *testing.T) {
+		response := performRequest(router, "DELETE", "/v1/webhooks/prize-desk", nil)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		response = performRequest(router, "GET", "/v1/webhooks/prize-desk", nil)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/cmd/cli"
+	"github.com/boothgames/nightfury/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// envPrefix prefixes the environment variables overriding the config, bind-port is read from NIGHTFURY_BIND_PORT
+const envPrefix = "nightfury"
+
+const maskedSecret = "********"
+
+// configErr holds the error reading the config file, if any
+var configErr error
+
+// serverConfig represents the options of the server, each key matches the flag of the same name
+type serverConfig struct {
+	BindAddress string `mapstructure:"bind-address" yaml:"bind-address"`
+	BindPort    int    `mapstructure:"bind-port" yaml:"bind-port"`
+	DBPath      string `mapstructure:"db-path" yaml:"db-path"`
+	LogLevel    string `mapstructure:"log-level" yaml:"log-level"`
+	LogFormat   string `mapstructure:"log-format" yaml:"log-format"`
+
+	JoinTokens      bool          `mapstructure:"join-tokens" yaml:"join-tokens"`
+	JoinTokenSecret string        `mapstructure:"join-token-secret" yaml:"join-token-secret"`
+	JoinTokenTTL    time.Duration `mapstructure:"join-token-ttl" yaml:"join-token-ttl"`
+	ProvisioningKey string        `mapstructure:"provisioning-key" yaml:"provisioning-key"`
+
+	TLSCert          string `mapstructure:"tls-cert" yaml:"tls-cert"`
+	TLSKey           string `mapstructure:"tls-key" yaml:"tls-key"`
+	TLSSelfSigned    bool   `mapstructure:"tls-self-signed" yaml:"tls-self-signed"`
+	RedirectHTTPPort int    `mapstructure:"redirect-http-port" yaml:"redirect-http-port"`
+
+	ShutdownTimeout    time.Duration `mapstructure:"shutdown-timeout" yaml:"shutdown-timeout"`
+	PurgeClientsOnExit bool          `mapstructure:"purge-clients-on-exit" yaml:"purge-clients-on-exit"`
+
+	HeartbeatInterval  time.Duration `mapstructure:"heartbeat-interval" yaml:"heartbeat-interval"`
+	HeartbeatTimeout   time.Duration `mapstructure:"heartbeat-timeout" yaml:"heartbeat-timeout"`
+	StaleClientTimeout time.Duration `mapstructure:"stale-client-timeout" yaml:"stale-client-timeout"`
+	ResumeWindow       time.Duration `mapstructure:"resume-window" yaml:"resume-window"`
+	EventBufferSize    int           `mapstructure:"event-buffer-size" yaml:"event-buffer-size"`
+
+	RequireRegistration bool `mapstructure:"require-registration" yaml:"require-registration"`
+}
+
+// configProblems represents everything wrong with a config
+type configProblems []string
+
+func (p configProblems) Error() string {
+	return fmt.Sprintf("invalid config: %v", strings.Join(p, "; "))
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the server config merged from flags, environment and config file",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective server config as yaml, secrets masked",
+	Run: func(cmd *cobra.Command, args []string) {
+		cli.DieIf(configErr)
+		config, err := loadServerConfig(viper.GetViper())
+		cli.DieIf(err)
+		out, err := yaml.Marshal(config.Masked())
+		cli.DieIf(err)
+		_, _ = cmd.OutOrStdout().Write(out)
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the effective server config and report every problem found",
+	Run: func(cmd *cobra.Command, args []string) {
+		problems := validateConfig(viper.GetViper(), configErr)
+		if len(problems) == 0 {
+			cli.Success("config is valid")
+			return
+		}
+		for _, problem := range problems {
+			cli.Error(problem)
+		}
+		os.Exit(1)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
+
+// configureEnv makes the config keys readable from NIGHTFURY_ prefixed environment variables
+func configureEnv(v *viper.Viper) {
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	v.AutomaticEnv()
+}
+
+// bindServerFlags makes the server flags the defaults of the config, overridden only when set explicitly
+func bindServerFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	cli.DieIf(v.BindPFlags(flags))
+}
+
+// loadServerConfig returns the server config merged from flags, environment and config file
+func loadServerConfig(v *viper.Viper) (serverConfig, error) {
+	config := serverConfig{}
+	if err := v.Unmarshal(&config); err != nil {
+		return config, fmt.Errorf("unable to read config, reason %v", err)
+	}
+	return config, nil
+}
+
+// validateConfig returns the problems of the config, including those reading the config file
+func validateConfig(v *viper.Viper, readErr error) []string {
+	var problems []string
+	if readErr != nil {
+		problems = append(problems, readErr.Error())
+	}
+	for _, key := range unknownKeys(v, serverCmd.Flags()) {
+		problems = append(problems, fmt.Sprintf("unknown key %v in config file", key))
+	}
+	config, err := loadServerConfig(v)
+	if err != nil {
+		return append(problems, err.Error())
+	}
+	if err := config.Validate(); err != nil {
+		problems = append(problems, err.(configProblems)...)
+	}
+	return problems
+}
+
+// unknownKeys returns the config file keys which are not server options, usually a typo
+func unknownKeys(v *viper.Viper, flags *pflag.FlagSet) []string {
+	var unknown []string
+	for _, key := range v.AllKeys() {
+		if flags.Lookup(key) == nil {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// Validate returns configProblems listing every invalid option
+func (c serverConfig) Validate() error {
+	var problems configProblems
+	if c.BindPort < 1 || c.BindPort > 65535 {
+		problems = append(problems, fmt.Sprintf("bind-port %v must be between 1 and 65535", c.BindPort))
+	}
+	if c.DBPath == "" {
+		problems = append(problems, "db-path is required")
+	}
+	if err := log.ValidateLogLevel(c.LogLevel); err != nil {
+		problems = append(problems, fmt.Sprintf("log-level %v", err))
+	}
+	if err := log.ValidateLogFormat(c.LogFormat); err != nil {
+		problems = append(problems, fmt.Sprintf("log-format %v", err))
+	}
+
+	if c.JoinTokens && c.ProvisioningKey == "" {
+		problems = append(problems, "provisioning-key is required when join-tokens is enabled")
+	}
+
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		problems = append(problems, "tls-cert and tls-key must be given together")
+	}
+	if c.TLSSelfSigned && (c.TLSCert != "" || c.TLSKey != "") {
+		problems = append(problems, "tls-self-signed cannot be used along with tls-cert and tls-key")
+	}
+	for key, path := range map[string]string{"tls-cert": c.TLSCert, "tls-key": c.TLSKey} {
+		if _, err := os.Stat(path); path != "" && err != nil {
+			problems = append(problems, fmt.Sprintf("%v %v", key, err))
+		}
+	}
+	if c.RedirectHTTPPort < 0 || c.RedirectHTTPPort > 65535 {
+		problems = append(problems, fmt.Sprintf("redirect-http-port %v must be between 0 and 65535", c.RedirectHTTPPort))
+	}
+	if c.RedirectHTTPPort != 0 && !c.TLS() {
+		problems = append(problems, "redirect-http-port requires tls-cert and tls-key or tls-self-signed")
+	}
+	if c.RedirectHTTPPort != 0 && c.RedirectHTTPPort == c.BindPort {
+		problems = append(problems, "redirect-http-port must differ from bind-port")
+	}
+
+	durations := map[string]time.Duration{
+		"join-token-ttl":       c.JoinTokenTTL,
+		"shutdown-timeout":     c.ShutdownTimeout,
+		"heartbeat-interval":   c.HeartbeatInterval,
+		"heartbeat-timeout":    c.HeartbeatTimeout,
+		"stale-client-timeout": c.StaleClientTimeout,
+		"resume-window":        c.ResumeWindow,
+	}
+	for key, duration := range durations {
+		if duration <= 0 {
+			problems = append(problems, fmt.Sprintf("%v must be positive", key))
+		}
+	}
+	if c.HeartbeatTimeout <= c.HeartbeatInterval {
+		problems = append(problems, "heartbeat-timeout must be longer than heartbeat-interval")
+	}
+	if c.EventBufferSize < 0 {
+		problems = append(problems, "event-buffer-size cannot be negative")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// TLS returns true when the server serves https and wss
+func (c serverConfig) TLS() bool {
+	return c.TLSCert != "" || c.TLSKey != "" || c.TLSSelfSigned
+}
+
+// Masked returns the config with its secrets hidden, to be printed
+func (c serverConfig) Masked() serverConfig {
+	if c.JoinTokenSecret != "" {
+		c.JoinTokenSecret = maskedSecret
+	}
+	if c.ProvisioningKey != "" {
+		c.ProvisioningKey = maskedSecret
+	}
+	return c
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestViper(t *testing.T, configFile string) *viper.Viper {
+	v := viper.New()
+	configureEnv(v)
+	bindServerFlags(v, serverCmd.Flags())
+	v.SetConfigType("yaml")
+	assert.NoError(t, v.ReadConfig(strings.NewReader(configFile)))
+	return v
+}
+
+func TestLoadServerConfig(t *testing.T) {
+	t.Run("should default to the flag values", func(t *testing.T) {
+		config, err := loadServerConfig(newTestViper(t, ""))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "0.0.0.0", config.BindAddress)
+		assert.Equal(t, 5624, config.BindPort)
+		assert.Equal(t, 15*time.Minute, config.JoinTokenTTL)
+		assert.Equal(t, 1000, config.EventBufferSize)
+		assert.NoError(t, config.Validate())
+	})
+
+	t.Run("should read the options from the config file", func(t *testing.T) {
+		configFile := `
+bind-port: 8080
+log-level: debug
+join-tokens: true
+provisioning-key: booth-key
+heartbeat-interval: 5s
+require-registration: true
+`
+		config, err := loadServerConfig(newTestViper(t, configFile))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 8080, config.BindPort)
+		assert.Equal(t, "debug", config.LogLevel)
+		assert.True(t, config.JoinTokens)
+		assert.Equal(t, "booth-key", config.ProvisioningKey)
+		assert.Equal(t, 5*time.Second, config.HeartbeatInterval)
+		assert.True(t, config.RequireRegistration)
+	})
+
+	t.Run("should prefer the environment over the config file", func(t *testing.T) {
+		_ = os.Setenv("NIGHTFURY_BIND_PORT", "9090")
+		_ = os.Setenv("NIGHTFURY_RESUME_WINDOW", "1m")
+		defer func() {
+			_ = os.Unsetenv("NIGHTFURY_BIND_PORT")
+			_ = os.Unsetenv("NIGHTFURY_RESUME_WINDOW")
+		}()
+
+		config, err := loadServerConfig(newTestViper(t, "bind-port: 8080\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 9090, config.BindPort)
+		assert.Equal(t, time.Minute, config.ResumeWindow)
+	})
+
+	t.Run("should return error when an option cannot be decoded", func(t *testing.T) {
+		_, err := loadServerConfig(newTestViper(t, "bind-port: http\n"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestServerConfigValidate(t *testing.T) {
+	valid, err := loadServerConfig(newTestViper(t, ""))
+	assert.NoError(t, err)
+
+	t.Run("should report every invalid option", func(t *testing.T) {
+		config := valid
+		config.BindPort = 0
+		config.LogFormat = "xml"
+		config.JoinTokens = true
+		config.HeartbeatTimeout = config.HeartbeatInterval
+		config.RedirectHTTPPort = 8080
+
+		err := config.Validate()
+
+		assert.Equal(t, configProblems{
+			"bind-port 0 must be between 1 and 65535",
+			"heartbeat-timeout must be longer than heartbeat-interval",
+			"log-format unknown log format xml, expected json or text",
+			"provisioning-key is required when join-tokens is enabled",
+			"redirect-http-port requires tls-cert and tls-key or tls-self-signed",
+		}, err)
+	})
+
+	t.Run("should refuse a self signed certificate along with a certificate file", func(t *testing.T) {
+		config := valid
+		config.TLSSelfSigned = true
+		config.TLSCert = "missing.pem"
+
+		err := config.Validate()
+
+		assert.Contains(t, err, "tls-cert and tls-key must be given together")
+		assert.Contains(t, err, "tls-self-signed cannot be used along with tls-cert and tls-key")
+	})
+}
+
+func TestValidateConfig(t *testing.T) {
+	t.Run("should report unknown keys of the config file", func(t *testing.T) {
+		problems := validateConfig(newTestViper(t, "bind-prot: 8080\n"), nil)
+
+		assert.Equal(t, []string{"unknown key bind-prot in config file"}, problems)
+	})
+}
+
+func TestServerConfigMasked(t *testing.T) {
+	t.Run("should hide the secrets when shown", func(t *testing.T) {
+		config, err := loadServerConfig(newTestViper(t, "join-token-secret: s3cret\nprovisioning-key: booth-key\n"))
+		assert.NoError(t, err)
+
+		out, err := yaml.Marshal(config.Masked())
+
+		assert.NoError(t, err)
+		assert.Contains(t, string(out), "join-token-secret: '********'")
+		assert.Contains(t, string(out), "provisioning-key: '********'")
+		assert.Contains(t, string(out), "join-token-ttl: 15m0s")
+		assert.NotContains(t, string(out), "s3cret")
+	})
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/cmd/cli"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/spf13/cobra"
+	"io"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	replayClient string
+	replayDBPath string
+	replayEvent  string
+	replayApply  bool
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Rebuild the state of a client from its gameplay history and print the timeline",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := db.Initialize(replayDBPath)
+		cli.DieIf(err)
+		defer func() { _ = db.Close() }()
+
+		if replayEvent == "" {
+			event, ok, err := nightfury.ActiveEvent(db.GlobalRepository())
+			cli.DieIf(err)
+			if ok {
+				replayEvent = event.ID()
+			}
+		}
+		db.ActivateScope(replayEvent)
+		repository := db.DefaultRepository()
+
+		history, err := nightfury.NewHistoryFromRepoWithName(repository, replayClient)
+		cli.DieIf(err)
+		if len(history.Events) == 0 {
+			cli.DieIf(fmt.Errorf("no gameplay history recorded for client %v", replayClient))
+		}
+		statuses := history.Replay()
+		printTimeline(cmd.OutOrStdout(), history, statuses)
+
+		client, err := nightfury.NewClientFromRepoWithName(repository, replayClient)
+		if _, ok := err.(db.EntryNotFound); ok {
+			cli.Warnf("client %v has been deleted", replayClient)
+			return
+		}
+		cli.DieIf(err)
+		if reflect.DeepEqual(client.GameStatuses, statuses) {
+			return
+		}
+		if !replayApply {
+			cli.Warn("the stored statuses differ from the replayed ones, run with --apply to replace them")
+			return
+		}
+		client.GameStatuses = statuses
+		cli.DieIf(client.Save(repository))
+		cli.Success("replaced the stored statuses with the replayed ones")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().StringVarP(&replayClient, "client", "c", "", "specify the client to replay")
+	replayCmd.Flags().StringVarP(&replayDBPath, "db-path", "", "nightfury.db", "specify the database path where db is stored")
+	replayCmd.Flags().StringVarP(&replayEvent, "event", "", "", "specify the event the client played in (defaults to the active event)")
+	replayCmd.Flags().BoolVarP(&replayApply, "apply", "", false, "replace the stored statuses of the client with the replayed ones")
+	_ = replayCmd.MarkFlagRequired("client")
+}
+
+// printTimeline writes the gameplay events of the history followed by the statuses they result in
+func printTimeline(out io.Writer, history nightfury.History, statuses nightfury.GameStatuses) {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(writer, "AT\tEVENT\tGAME\tSTATUS\tPROGRESS\n")
+	for _, event := range history.Events {
+		status := ""
+		if event.Type != nightfury.RunStarted && event.Type != nightfury.GameProgress {
+			status = event.Status.String()
+		}
+		_, _ = fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n",
+			event.At.UTC().Format(time.RFC3339), event.Type, event.Game, status, formatProgress(event.Progress))
+	}
+	_ = writer.Flush()
+
+	_, _ = fmt.Fprintf(out, "\nstatuses of client %v\n", history.Client)
+	writer = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(writer, "GAME\tSTATUS\tPROGRESS\n")
+	for _, name := range sortedNames(statuses) {
+		_, _ = fmt.Fprintf(writer, "%v\t%v\t%v\n", name, statuses[name].Status, formatProgress(statuses[name].Progress))
+	}
+	_ = writer.Flush()
+}
+
+func formatProgress(progress *nightfury.Progress) string {
+	if progress == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v%% score %v", progress.Percentage, progress.Score)
+}
+
+func sortedNames(statuses nightfury.GameStatuses) []string {
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPrintTimeline(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	history := nightfury.History{Client: "kiosk-1", Events: []nightfury.GameplayEvent{
+		{Type: nightfury.GameAdded, Game: "snakes", Status: nightfury.Ready, At: at},
+		{Type: nightfury.RunStarted, At: at.Add(time.Second)},
+		{Type: nightfury.GameStarted, Game: "snakes", Status: nightfury.InProgress, At: at.Add(time.Second)},
+		{Type: nightfury.GameProgress, Game: "snakes", Progress: &nightfury.Progress{Percentage: 40, Score: 120}, At: at.Add(2 * time.Second)},
+	}}
+	out := &bytes.Buffer{}
+
+	printTimeline(out, history, history.Replay())
+
+	expected := `AT                    EVENT     GAME    STATUS      PROGRESS
+2019-10-01T10:00:00Z  added     snakes  Ready       
+2019-10-01T10:00:01Z  start                         
+2019-10-01T10:00:01Z  started   snakes  InProgress  
+2019-10-01T10:00:02Z  progress  snakes              40% score 120
+
+statuses of client kiosk-1
+GAME    STATUS      PROGRESS
+snakes  InProgress  40% score 120
+`
+	assert.Equal(t, expected, out.String())
+}
@@ -49,10 +49,16 @@ func initConfig() {
 		viper.SetConfigName(".nightfury")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	configureEnv(viper.GetViper()) // read in NIGHTFURY_ prefixed environment variables that match
 
 	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	err := viper.ReadInConfig()
+	if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		return
 	}
+	if err != nil {
+		configErr = fmt.Errorf("unable to read config file, reason %v", err)
+		return
+	}
+	_, _ = fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 }
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/api/socket"
+	"github.com/boothgames/nightfury/cmd/cli"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+)
+
+var schemaOutput string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the socket protocol",
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := json.MarshalIndent(socket.Schema(), "", "  ")
+		cli.DieIf(err)
+		if schemaOutput == "" {
+			fmt.Println(string(data))
+			return
+		}
+		err = ioutil.WriteFile(schemaOutput, append(data, '\n'), 0644)
+		cli.DieIf(err)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.Flags().StringVarP(&schemaOutput, "output", "o", "", "specify the file to write the schema to")
+}
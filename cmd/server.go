@@ -1,20 +1,30 @@
 package cmd
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/boothgames/nightfury/api"
+	"github.com/boothgames/nightfury/api/events"
+	"github.com/boothgames/nightfury/api/socket"
 	"github.com/boothgames/nightfury/cmd/cli"
 	"github.com/boothgames/nightfury/log"
+	"github.com/boothgames/nightfury/pkg/certificate"
 	"github.com/boothgames/nightfury/pkg/db"
 	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/boothgames/nightfury/pkg/token"
+	"github.com/boothgames/nightfury/pkg/webhook"
 	"github.com/gin-gonic/gin"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 )
 
 // serverCmd represents the server command
@@ -22,63 +32,208 @@ var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "Start nightfury server",
 	Run: func(cmd *cobra.Command, args []string) {
-		log.SetLogLevel(logLevel)
-		go startServer()
+		cli.DieIf(configErr)
+		config, err := loadServerConfig(viper.GetViper())
+		cli.DieIf(err)
+		cli.DieIf(config.Validate())
+		for _, key := range unknownKeys(viper.GetViper(), cmd.Flags()) {
+			cli.Warnf("ignoring unknown key %v in config file", key)
+		}
+
+		log.SetLogLevel(config.LogLevel)
+		cli.DieIf(log.SetLogFormat(config.LogFormat))
+		srv := newServer(config)
+		redirect := newRedirectServer(srv, config)
+		go startServer(srv, redirect)
 
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 		<-quit
 
 		cli.Warn("\ngracefully shutting down server ...")
-		cleanup()
+		shutdown(srv, redirect, config)
 		cli.Success("done")
 	},
 }
 
-var (
-	bindAddress string
-	bindPort    int
-	dbPath      string
-	logLevel    string
-)
-
 func init() {
 	rootCmd.AddCommand(serverCmd)
-	serverCmd.Flags().StringVarP(&bindAddress, "bind-address", "", "0.0.0.0", "specify the advertise address to use")
-	serverCmd.Flags().IntVarP(&bindPort, "bind-port", "p", 5624, "specify the advertise port to use")
-	serverCmd.Flags().StringVarP(&logLevel, "log-level", "l", "error", "specify the log level (panic, fatal, error, warn, info, debug, trace)")
-	serverCmd.Flags().StringVarP(&dbPath, "db-path", "", "nightfury.db", "specify the database path where db will be stored")
+	flags := serverCmd.Flags()
+	flags.StringP("bind-address", "", "0.0.0.0", "specify the advertise address to use")
+	flags.IntP("bind-port", "p", 5624, "specify the advertise port to use")
+	flags.StringP("log-level", "l", "info", "specify the log level (panic, fatal, error, warn, info, debug, trace)")
+	flags.StringP("log-format", "", "text", "specify the log format (json, text)")
+	flags.StringP("db-path", "", "nightfury.db", "specify the database path where db will be stored")
+	flags.BoolP("join-tokens", "", false, "require signed join tokens to open client and game sockets")
+	flags.StringP("join-token-secret", "", "", "specify the secret used to sign join tokens (random when empty)")
+	flags.DurationP("join-token-ttl", "", 15*time.Minute, "specify how long a join token stays valid")
+	flags.StringP("provisioning-key", "", "", "specify the key kiosks present to fetch their join tokens")
+	flags.StringP("tls-cert", "", "", "specify the certificate file to serve https and wss")
+	flags.StringP("tls-key", "", "", "specify the private key file of the tls certificate")
+	flags.BoolP("tls-self-signed", "", false, "serve https and wss with a self signed certificate generated at startup")
+	flags.IntP("redirect-http-port", "", 0, "specify the port which redirects http to https (disabled when 0)")
+	flags.DurationP("shutdown-timeout", "", 10*time.Second, "specify how long to wait for requests, sockets and webhook deliveries to drain on shutdown")
+	flags.BoolP("purge-clients-on-exit", "", false, "delete all clients from db on shutdown")
+	flags.DurationP("heartbeat-interval", "", 10*time.Second, "specify how often client and game sockets are pinged")
+	flags.DurationP("heartbeat-timeout", "", 30*time.Second, "specify how long to wait for a pong before closing a socket")
+	flags.DurationP("resume-window", "", 30*time.Second, "specify how long a disconnected game keeps its status for the game to resume")
+	flags.IntP("event-buffer-size", "", 1000, "specify how many recent events are kept for server-sent event consumers to resume from")
+	flags.DurationP("stale-client-timeout", "", time.Minute, "specify how long a client can go unseen before it is marked unavailable")
+	flags.BoolP("require-registration", "", false, "require a player to register on a client before it starts its games")
+	bindServerFlags(viper.GetViper(), flags)
 }
 
-func releaseMode() string {
+func releaseMode(logLevel string) string {
 	switch strings.ToLower(logLevel) {
-	case "panic", "fatal", "error":
-		return gin.ReleaseMode
-	default:
+	case "debug", "trace":
 		return gin.DebugMode
+	default:
+		return gin.ReleaseMode
 	}
 }
 
-func startServer() {
-	address := fmt.Sprintf("%s:%d", bindAddress, bindPort)
+func newServer(config serverConfig) *http.Server {
+	address := fmt.Sprintf("%s:%d", config.BindAddress, config.BindPort)
 	cli.Info(fmt.Sprintf("starting nightfury at %s", address))
 
-	gin.SetMode(releaseMode())
-	router := gin.Default()
+	gin.SetMode(releaseMode(config.LogLevel))
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	err := db.Initialize(config.DBPath)
+	cli.DieIf(err)
 
-	err := db.Initialize(dbPath)
+	event, ok, err := nightfury.ActiveEvent(db.GlobalRepository())
 	cli.DieIf(err)
+	if ok {
+		cli.Info(fmt.Sprintf("scoping data to event %s", event.Name))
+		db.ActivateScope(event.ID())
+	}
+
+	if config.JoinTokens {
+		err = token.Initialize(config.JoinTokenSecret, config.JoinTokenTTL)
+		cli.DieIf(err)
+		api.SetProvisioningKey(config.ProvisioningKey)
+	} else {
+		cli.Warn("join tokens are disabled, anyone reaching the server can open client and game sockets and report games as completed")
+	}
 
+	events.ConfigureBuffer(config.EventBufferSize)
+	nightfury.RequireRegistration(config.RequireRegistration)
 	api.Bind(router)
+	socket.ConfigureHeartbeat(config.HeartbeatInterval, config.HeartbeatTimeout)
+	socket.ConfigureResumeWindow(config.ResumeWindow)
+	go socket.StartReaper(config.HeartbeatInterval, config.StaleClientTimeout)
+
 	srv := &http.Server{Addr: address, Handler: router}
 
+	srv.TLSConfig, err = serverTLSConfig(config)
+	cli.DieIf(err)
+	return srv
+}
+
+func startServer(srv *http.Server, redirect *http.Server) {
+	var err error
+
 	// service connections
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if srv.TLSConfig == nil {
+		err = srv.ListenAndServe()
+	} else {
+		if redirect != nil {
+			go startRedirectServer(redirect)
+		}
+		err = srv.ListenAndServeTLS("", "")
+	}
+	if err != nil && err != http.ErrServerClosed {
 		cli.DieIf(err)
 	}
 }
 
-func cleanup() {
+// serverTLSConfig returns nil when the server should serve plain http
+func serverTLSConfig(config serverConfig) (*tls.Config, error) {
+	if config.TLSCert != "" || config.TLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCert, config.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load tls certificate, reason %v", err)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	}
+	if config.TLSSelfSigned {
+		cli.Warn("serving with a self signed certificate, browsers will ask to trust it")
+		cert, err := certificate.SelfSigned(certificate.LocalHosts()...)
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	}
+	return nil, nil
+}
+
+// newRedirectServer returns the server redirecting http to https, nil unless https is served with a redirect port
+func newRedirectServer(srv *http.Server, config serverConfig) *http.Server {
+	if srv.TLSConfig == nil || config.RedirectHTTPPort == 0 {
+		return nil
+	}
+	address := fmt.Sprintf("%s:%d", config.BindAddress, config.RedirectHTTPPort)
+	return &http.Server{Addr: address, Handler: httpsRedirect(config.BindPort)}
+}
+
+func startRedirectServer(redirect *http.Server) {
+	cli.Info(fmt.Sprintf("redirecting http at %s to https", redirect.Addr))
+	if err := redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		cli.DieIf(err)
+	}
+}
+
+// httpsRedirect redirects every request to the same host and path served over https at port
+func httpsRedirect(port int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		target := fmt.Sprintf("https://%s%s", net.JoinHostPort(host, fmt.Sprintf("%d", port)), r.URL.RequestURI())
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+}
+
+func shutdown(srv *http.Server, redirect *http.Server, config serverConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	cli.Warn("closing event streams")
+	events.Close()
+
+	cli.Warn("stopping http server")
+	if err := srv.Shutdown(ctx); err != nil {
+		cli.Errorf("http server did not stop cleanly, reason %v", err)
+	}
+	if redirect != nil {
+		if err := redirect.Shutdown(ctx); err != nil {
+			cli.Errorf("http redirect server did not stop cleanly, reason %v", err)
+		}
+	}
+
+	cli.Warn("draining sockets")
+	if err := socket.Shutdown(config.ShutdownTimeout); err != nil {
+		cli.Errorf("sockets did not drain cleanly, reason %v", err)
+	}
+
+	cli.Warn("waiting for webhook deliveries")
+	if err := webhook.DefaultDispatcher().Drain(config.ShutdownTimeout); err != nil {
+		cli.Errorf("webhooks were not all notified, reason %v", err)
+	}
+
+	if config.PurgeClientsOnExit {
+		purgeClients()
+	}
+
+	cli.Warn("shutting down db")
+	err := db.Close()
+	cli.DieIf(err)
+}
+
+func purgeClients() {
 	clients := nightfury.Clients{}
 	repository := db.DefaultRepository()
 	clientsFromRepo, err := nightfury.NewClientsFromRepo(repository)
@@ -91,8 +246,4 @@ func cleanup() {
 
 	err = clients.Delete(repository)
 	cli.DieIf(err)
-
-	cli.Warn("shutting down db")
-	err = db.Close()
-	cli.DieIf(err)
 }
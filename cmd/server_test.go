@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSRedirect(t *testing.T) {
+	t.Run("should redirect to the https port keeping host and path", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "http://booth.local:8080/ws/v1/clients/kiosk-1?token=abc", nil)
+		response := httptest.NewRecorder()
+
+		httpsRedirect(5624).ServeHTTP(response, request)
+
+		assert.Equal(t, http.StatusMovedPermanently, response.Code)
+		assert.Equal(t, "https://booth.local:5624/ws/v1/clients/kiosk-1?token=abc", response.Header().Get("Location"))
+	})
+}
+
+func TestReleaseMode(t *testing.T) {
+	assert.Equal(t, gin.ReleaseMode, releaseMode("info"))
+	assert.Equal(t, gin.ReleaseMode, releaseMode("error"))
+	assert.Equal(t, gin.DebugMode, releaseMode("DEBUG"))
+}
+
+func TestNewRedirectServer(t *testing.T) {
+	config := serverConfig{BindAddress: "127.0.0.1", BindPort: 8443, RedirectHTTPPort: 8080}
+
+	t.Run("should redirect when serving https", func(t *testing.T) {
+		redirect := newRedirectServer(&http.Server{TLSConfig: &tls.Config{}}, config)
+
+		if assert.NotNil(t, redirect) {
+			assert.Equal(t, "127.0.0.1:8080", redirect.Addr)
+		}
+	})
+
+	t.Run("should not redirect when serving http", func(t *testing.T) {
+		assert.Nil(t, newRedirectServer(&http.Server{}, config))
+	})
+}
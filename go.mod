@@ -9,13 +9,18 @@ require (
 	github.com/gin-gonic/gin v1.4.0
 	github.com/golang/mock v1.3.1
 	github.com/google/go-cmp v0.3.1
+	github.com/gorilla/websocket v1.4.0
 	github.com/mattn/go-colorable v0.1.4 // indirect
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/mitchellh/mapstructure v1.1.2
+	github.com/prometheus/client_golang v1.1.0
+	github.com/prometheus/client_model v0.0.0-20190129233127-fd36f4220a90
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/cobra v0.0.5
+	github.com/spf13/pflag v1.0.3
 	github.com/spf13/viper v1.4.0
 	github.com/stretchr/testify v1.4.0
 	go.etcd.io/bbolt v1.3.3
 	gopkg.in/olahol/melody.v1 v1.0.0-20170518105555-d52139073376
+	gopkg.in/yaml.v2 v2.2.2
 )
@@ -0,0 +1,53 @@
+package log
+
+import "github.com/sirupsen/logrus"
+
+// Entry represents a logger carrying contextual fields
+type Entry struct {
+	entry *logrus.Entry
+}
+
+// WithFields returns an entry logging the fields along with the fields of the entry
+func (e Entry) WithFields(fields Fields) Entry {
+	return Entry{entry: e.entry.WithFields(logrus.Fields(fields))}
+}
+
+// Info logs info level logs
+func (e Entry) Info(args ...interface{}) {
+	e.entry.Info(args...)
+}
+
+// Infof logs info level logs
+func (e Entry) Infof(format string, args ...interface{}) {
+	e.entry.Infof(format, args...)
+}
+
+// Debug logs debug level logs
+func (e Entry) Debug(args ...interface{}) {
+	e.entry.Debug(args...)
+}
+
+// Debugf logs debug level logs
+func (e Entry) Debugf(format string, args ...interface{}) {
+	e.entry.Debugf(format, args...)
+}
+
+// Warn logs warn level logs
+func (e Entry) Warn(args ...interface{}) {
+	e.entry.Warn(args...)
+}
+
+// Warnf logs warn level logs
+func (e Entry) Warnf(format string, args ...interface{}) {
+	e.entry.Warnf(format, args...)
+}
+
+// Error logs error level logs
+func (e Entry) Error(args ...interface{}) {
+	e.entry.Error(args...)
+}
+
+// Errorf logs error level logs
+func (e Entry) Errorf(format string, args ...interface{}) {
+	e.entry.Errorf(format, args...)
+}
@@ -1,7 +1,20 @@
 package log
 
 import (
+	"fmt"
 	"github.com/sirupsen/logrus"
+	"io"
+)
+
+// Fields represents the contextual fields of a log entry
+type Fields map[string]interface{}
+
+// Names of the fields correlating the logs of a kiosk
+const (
+	ClientField    = "client"
+	GameField      = "game"
+	ActionField    = "action"
+	RequestIDField = "request_id"
 )
 
 var logger *logrus.Logger
@@ -25,6 +38,21 @@ func Debug(args ...interface{}) {
 	logger.Debug(args...)
 }
 
+// Debugf logs debug level logs
+func Debugf(format string, args ...interface{}) {
+	logger.Debugf(format, args...)
+}
+
+// Warn logs warn level logs
+func Warn(args ...interface{}) {
+	logger.Warn(args...)
+}
+
+// Warnf logs warn level logs
+func Warnf(format string, args ...interface{}) {
+	logger.Warnf(format, args...)
+}
+
 // Error logs error level logs
 func Error(args ...interface{}) {
 	logger.Error(args...)
@@ -35,6 +63,11 @@ func Errorf(format string, args ...interface{}) {
 	logger.Errorf(format, args...)
 }
 
+// WithFields returns an entry logging the fields along with its logs
+func WithFields(fields Fields) Entry {
+	return Entry{entry: logger.WithFields(logrus.Fields(fields))}
+}
+
 // SetLogLevel sets the logger level.
 func SetLogLevel(level string) {
 	logLevel, err := logrus.ParseLevel(level)
@@ -43,3 +76,39 @@ func SetLogLevel(level string) {
 	}
 	logger.SetLevel(logLevel)
 }
+
+// ValidateLogLevel returns error if the level is not one of the log levels
+func ValidateLogLevel(level string) error {
+	_, err := logrus.ParseLevel(level)
+	return err
+}
+
+// ValidateLogFormat returns error if the format is neither json nor text
+func ValidateLogFormat(format string) error {
+	if format != "json" && format != "text" {
+		return fmt.Errorf("unknown log format %v, expected json or text", format)
+	}
+	return nil
+}
+
+// SetLogFormat sets the logger output format, either json or text
+func SetLogFormat(format string) error {
+	switch format {
+	case "json":
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case "text":
+		logger.SetFormatter(&logrus.TextFormatter{})
+	default:
+		return ValidateLogFormat(format)
+	}
+	return nil
+}
+
+// SetOutput sets the logger output and returns a func restoring the previous output
+func SetOutput(out io.Writer) func() {
+	original := logger.Out
+	logger.SetOutput(out)
+	return func() {
+		logger.SetOutput(original)
+	}
+}
@@ -0,0 +1,42 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/boothgames/nightfury/log"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	restore := log.SetOutput(out)
+	defer restore()
+	_ = log.SetLogFormat("json")
+	defer func() { _ = log.SetLogFormat("text") }()
+
+	log.WithFields(log.Fields{log.ClientField: "kiosk-1"}).
+		WithFields(log.Fields{log.GameField: "snakes"}).
+		Warnf("game '%v' is slow", "snakes")
+
+	actual := map[string]interface{}{}
+	err := json.Unmarshal(out.Bytes(), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, "kiosk-1", actual["client"])
+	assert.Equal(t, "snakes", actual["game"])
+	assert.Equal(t, "warning", actual["level"])
+	assert.Equal(t, "game 'snakes' is slow", actual["msg"])
+}
+
+func TestSetLogFormat(t *testing.T) {
+	t.Run("should accept json and text", func(t *testing.T) {
+		assert.NoError(t, log.SetLogFormat("json"))
+		assert.NoError(t, log.SetLogFormat("text"))
+	})
+
+	t.Run("should fail for unknown format", func(t *testing.T) {
+		err := log.SetLogFormat("xml")
+
+		assert.EqualError(t, err, "unknown log format xml, expected json or text")
+	})
+}
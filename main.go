@@ -3,6 +3,7 @@ package main
 import "github.com/boothgames/nightfury/cmd"
 
 //go:generate ./scripts/mocks
+//go:generate go run . schema --output docs/protocol.schema.json
 
 func main() {
 	cmd.Execute()
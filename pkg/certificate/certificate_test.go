@@ -0,0 +1,21 @@
+package certificate_test
+
+import (
+	"crypto/x509"
+	"github.com/boothgames/nightfury/pkg/certificate"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSelfSigned(t *testing.T) {
+	t.Run("should generate certificate for dns names and ip addresses", func(t *testing.T) {
+		cert, err := certificate.SelfSigned("localhost", "192.168.1.10")
+		assert.NoError(t, err)
+
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		assert.NoError(t, err)
+		assert.NoError(t, parsed.VerifyHostname("localhost"))
+		assert.NoError(t, parsed.VerifyHostname("192.168.1.10"))
+		assert.Error(t, parsed.VerifyHostname("example.com"))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"go.etcd.io/bbolt"
+	"time"
 )
 
 // BoltRepository represents a bbolt database
@@ -11,9 +12,12 @@ type BoltRepository struct {
 	db *bbolt.DB
 }
 
+// openTimeout is how long to wait for the lock of a db held by another process, e.g. a running server
+const openTimeout = time.Second
+
 // NewBoltRepository returns the repository and error if any
 func NewBoltRepository(path string) (Repository, error) {
-	instance, err := bbolt.Open(path, 0666, nil)
+	instance, err := bbolt.Open(path, 0666, &bbolt.Options{Timeout: openTimeout})
 	if err != nil {
 		return nil, fmt.Errorf("unable to open db, reason %v", err)
 	}
@@ -25,43 +29,50 @@ func (repo BoltRepository) Close() error {
 	return repo.db.Close()
 }
 
+// Stats returns the transaction and freelist stats of bbolt db
+func (repo BoltRepository) Stats() bbolt.Stats {
+	return repo.db.Stats()
+}
+
 // Delete deletes the model from bucketName
 func (repo BoltRepository) Delete(bucketName string, model Model) error {
-	return repo.db.Update(func(tx *bbolt.Tx) error {
-		bName := []byte(bucketName)
-		bucket := tx.Bucket(bName)
-		if bucket == nil {
-			clientsBucket, err := tx.CreateBucket(bName)
-			if err != nil {
-				return err
-			}
-			bucket = clientsBucket
-		}
-		return bucket.Delete([]byte(model.ID()))
-	})
+	return repo.Apply(Write{Bucket: bucketName, Model: model, Delete: true})
 }
 
 // Save persists the model in the bucketName
 func (repo BoltRepository) Save(bucketName string, model Model) error {
+	return repo.Apply(Write{Bucket: bucketName, Model: model})
+}
+
+// Apply saves and deletes the models in a single transaction, none of the writes is applied if one fails
+func (repo BoltRepository) Apply(writes ...Write) error {
 	return repo.db.Update(func(tx *bbolt.Tx) error {
-		bName := []byte(bucketName)
-		bucket := tx.Bucket(bName)
-		if bucket == nil {
-			clientsBucket, err := tx.CreateBucket(bName)
+		for _, write := range writes {
+			bucket, err := tx.CreateBucketIfNotExists([]byte(write.Bucket))
+			if err != nil {
+				return err
+			}
+			if write.Delete {
+				err = bucket.Delete([]byte(write.Model.ID()))
+			} else {
+				err = put(bucket, write.Model)
+			}
 			if err != nil {
 				return err
 			}
-			bucket = clientsBucket
-		}
-
-		bytes, err := json.Marshal(model)
-		if err != nil {
-			return err
 		}
-		return bucket.Put([]byte(model.ID()), bytes)
+		return nil
 	})
 }
 
+func put(bucket *bbolt.Bucket, model Model) error {
+	bytes, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(model.ID()), bytes)
+}
+
 // Fetch retrieves the model identified by name from the bucket named bucketName
 func (repo BoltRepository) Fetch(bucketName string, name string, model Model) (bool, error) {
 	populated := false
@@ -106,3 +117,25 @@ func (repo BoltRepository) FetchAll(bucketName string, modelFn func([]byte) (Mod
 	})
 	return result, err
 }
+
+// FetchFrom returns the models of the bucketName whose key sorts at or after from, in the order of their keys
+func (repo BoltRepository) FetchFrom(bucketName string, from string, modelFn func([]byte) (Model, error)) ([]Model, error) {
+	var result []Model
+	err := repo.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketName))
+		if bucket == nil {
+			return nil
+		}
+
+		cursor := bucket.Cursor()
+		for key, value := cursor.Seek([]byte(from)); key != nil; key, value = cursor.Next() {
+			model, err := modelFn(value)
+			if err != nil {
+				return err
+			}
+			result = append(result, model)
+		}
+		return nil
+	})
+	return result, err
+}
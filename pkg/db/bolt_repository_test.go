@@ -55,6 +55,45 @@ func TestBoltRepositoryDelete(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestBoltRepositoryApply(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "nightfury")
+	dbPath := path.Join(dir, "db")
+	repo, _ := db.NewBoltRepository(dbPath)
+
+	defer func() {
+		_ = repo.(db.BoltRepository).Close()
+		_ = os.RemoveAll(dir)
+	}()
+	_ = repo.Save("test", TestModel{Name: "stale"})
+
+	t.Run("should apply every write", func(t *testing.T) {
+		err := repo.Apply(
+			db.Write{Bucket: "test", Model: TestModel{Name: "saved"}},
+			db.Write{Bucket: "other", Model: TestModel{Name: "saved"}},
+			db.Write{Bucket: "test", Model: TestModel{Name: "stale"}, Delete: true},
+		)
+
+		assert.NoError(t, err)
+		ok, _ := repo.Fetch("test", "saved", &TestModel{})
+		assert.True(t, ok)
+		ok, _ = repo.Fetch("other", "saved", &TestModel{})
+		assert.True(t, ok)
+		ok, _ = repo.Fetch("test", "stale", &TestModel{})
+		assert.False(t, ok)
+	})
+
+	t.Run("should apply none of the writes when one fails", func(t *testing.T) {
+		err := repo.Apply(
+			db.Write{Bucket: "test", Model: TestModel{Name: "rolled back"}},
+			db.Write{Bucket: "test", Model: TestModel{Name: ""}},
+		)
+
+		assert.Error(t, err)
+		ok, _ := repo.Fetch("test", "rolled back", &TestModel{})
+		assert.False(t, ok)
+	})
+}
+
 func TestBoltRepositoryFetch(t *testing.T) {
 	dir, _ := ioutil.TempDir("", "nightfury")
 	dbPath := path.Join(dir, "db")
@@ -109,3 +148,37 @@ func TestBoltRepositoryFetchAll(t *testing.T) {
 		assert.Fail(t, cmp.Diff(expected, actual))
 	}
 }
+
+func TestBoltRepositoryFetchFrom(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "nightfury")
+	dbPath := path.Join(dir, "db")
+	repo, _ := db.NewBoltRepository(dbPath)
+
+	defer func() {
+		_ = repo.(db.BoltRepository).Close()
+		_ = os.RemoveAll(dir)
+	}()
+
+	_ = repo.Save("test", TestModel{Name: "0003"})
+	_ = repo.Save("test", TestModel{Name: "0001"})
+	_ = repo.Save("test", TestModel{Name: "0002"})
+	modelFn := func(bytes []byte) (db.Model, error) {
+		model := TestModel{}
+		err := json.Unmarshal(bytes, &model)
+		return model, err
+	}
+
+	t.Run("should return the models from the key in key order", func(t *testing.T) {
+		actual, err := repo.FetchFrom("test", "0002", modelFn)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []db.Model{TestModel{Name: "0002"}, TestModel{Name: "0003"}}, actual)
+	})
+
+	t.Run("should return nothing for a missing bucket", func(t *testing.T) {
+		actual, err := repo.FetchFrom("missing", "", modelFn)
+
+		assert.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+}
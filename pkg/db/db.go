@@ -1,19 +1,33 @@
 package db
 
+import "sync"
+
 var repository Repository
 
+var scope string
+var scopeLock = new(sync.RWMutex)
+
 // Model which can persisted in the repository
 type Model interface {
 	ID() string
 }
 
+// Write represents a model saved to, or deleted from, a bucket along with other writes
+type Write struct {
+	Bucket string
+	Model  Model
+	Delete bool
+}
+
 // Repository holds the necessary method to persist and retrieve data
 // from database
 type Repository interface {
 	Save(bucketName string, model Model) error
 	Delete(bucketName string, model Model) error
+	Apply(writes ...Write) error
 	Fetch(bucketName string, name string, model Model) (bool, error)
 	FetchAll(bucketName string, modelFn func(data []byte) (Model, error)) (interface{}, error)
+	FetchFrom(bucketName string, from string, modelFn func(data []byte) (Model, error)) ([]Model, error)
 	Close() error
 }
 
@@ -32,11 +46,35 @@ func Close() error {
 	return repository.Close()
 }
 
-// DefaultRepository returns the global repository
+// DefaultRepository returns the global repository, scoped to the active scope if any
 func DefaultRepository() Repository {
+	scopeLock.RLock()
+	defer scopeLock.RUnlock()
+	if scope == "" {
+		return repository
+	}
+	return NewScopedRepository(repository, scope)
+}
+
+// GlobalRepository returns the global repository regardless of the active scope
+func GlobalRepository() Repository {
 	return repository
 }
 
+// ActivateScope scopes the buckets of the default repository, empty name removes the scope
+func ActivateScope(name string) {
+	scopeLock.Lock()
+	defer scopeLock.Unlock()
+	scope = name
+}
+
+// ActiveScope returns the active scope, empty if the default repository is not scoped
+func ActiveScope() string {
+	scopeLock.RLock()
+	defer scopeLock.RUnlock()
+	return scope
+}
+
 // ReplaceDefaultRepositoryWith replace the default repository
 func ReplaceDefaultRepositoryWith(repo Repository) func() {
 	originalRepo := repository
@@ -0,0 +1,58 @@
+package db
+
+import "fmt"
+
+// ScopedRepository keeps the buckets of a scope apart from the buckets of other scopes
+type ScopedRepository struct {
+	repo  Repository
+	scope string
+}
+
+// NewScopedRepository returns the repository storing every bucket under scope
+func NewScopedRepository(repo Repository, scope string) Repository {
+	return ScopedRepository{repo: repo, scope: scope}
+}
+
+func (repo ScopedRepository) bucket(bucketName string) string {
+	return fmt.Sprintf("scopes/%v/%v", repo.scope, bucketName)
+}
+
+// Save persists the model in the bucketName of the scope
+func (repo ScopedRepository) Save(bucketName string, model Model) error {
+	return repo.repo.Save(repo.bucket(bucketName), model)
+}
+
+// Delete deletes the model from bucketName of the scope
+func (repo ScopedRepository) Delete(bucketName string, model Model) error {
+	return repo.repo.Delete(repo.bucket(bucketName), model)
+}
+
+// Apply saves and deletes the models in the buckets of the scope in a single transaction
+func (repo ScopedRepository) Apply(writes ...Write) error {
+	scoped := make([]Write, 0, len(writes))
+	for _, write := range writes {
+		write.Bucket = repo.bucket(write.Bucket)
+		scoped = append(scoped, write)
+	}
+	return repo.repo.Apply(scoped...)
+}
+
+// Fetch retrieves the model identified by name from the bucketName of the scope
+func (repo ScopedRepository) Fetch(bucketName string, name string, model Model) (bool, error) {
+	return repo.repo.Fetch(repo.bucket(bucketName), name, model)
+}
+
+// FetchAll returns all the models available in the bucketName of the scope
+func (repo ScopedRepository) FetchAll(bucketName string, modelFn func([]byte) (Model, error)) (interface{}, error) {
+	return repo.repo.FetchAll(repo.bucket(bucketName), modelFn)
+}
+
+// FetchFrom returns the models of the bucketName of the scope whose key sorts at or after from
+func (repo ScopedRepository) FetchFrom(bucketName string, from string, modelFn func([]byte) (Model, error)) ([]Model, error) {
+	return repo.repo.FetchFrom(repo.bucket(bucketName), from, modelFn)
+}
+
+// Close closes the underlying repository
+func (repo ScopedRepository) Close() error {
+	return repo.repo.Close()
+}
@@ -0,0 +1,92 @@
+package db_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestScopedRepository(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "nightfury")
+	dbPath := path.Join(dir, "db")
+	repo, _ := db.NewBoltRepository(dbPath)
+
+	defer func() {
+		_ = repo.Close()
+		_ = os.RemoveAll(dir)
+	}()
+
+	scoped := db.NewScopedRepository(repo, "devfest")
+	_ = scoped.Save("test", TestModel{Name: "scoped"})
+	_ = repo.Save("test", TestModel{Name: "global"})
+
+	t.Run("should fetch models saved in the scope", func(t *testing.T) {
+		actual := TestModel{}
+
+		ok, err := scoped.Fetch("test", "scoped", &actual)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, TestModel{Name: "scoped"}, actual)
+	})
+
+	t.Run("should not fetch models saved outside the scope", func(t *testing.T) {
+		ok, err := scoped.Fetch("test", "global", &TestModel{})
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("should fetch all models of the scope only", func(t *testing.T) {
+		actual, err := scoped.FetchAll("test", func(data []byte) (db.Model, error) {
+			model := TestModel{}
+			err := json.Unmarshal(data, &model)
+			return model, err
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"scoped": TestModel{Name: "scoped"}}, actual)
+	})
+
+	t.Run("should fetch the models from the key of the scope only", func(t *testing.T) {
+		actual, err := scoped.FetchFrom("test", "", func(data []byte) (db.Model, error) {
+			model := TestModel{}
+			err := json.Unmarshal(data, &model)
+			return model, err
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []db.Model{TestModel{Name: "scoped"}}, actual)
+	})
+
+	t.Run("should delete models of the scope only", func(t *testing.T) {
+		_ = scoped.Delete("test", TestModel{Name: "global"})
+
+		ok, _ := repo.Fetch("test", "global", &TestModel{})
+
+		assert.True(t, ok)
+	})
+
+	t.Run("should apply writes to the scope only", func(t *testing.T) {
+		err := scoped.Apply(db.Write{Bucket: "test", Model: TestModel{Name: "applied"}})
+
+		assert.NoError(t, err)
+		ok, _ := scoped.Fetch("test", "applied", &TestModel{})
+		assert.True(t, ok)
+		ok, _ = repo.Fetch("test", "applied", &TestModel{})
+		assert.False(t, ok)
+	})
+}
+
+func TestActivateScope(t *testing.T) {
+	defer db.ActivateScope("")
+
+	db.ActivateScope("devfest")
+
+	assert.Equal(t, "devfest", db.ActiveScope())
+	assert.IsType(t, db.ScopedRepository{}, db.DefaultRepository())
+}
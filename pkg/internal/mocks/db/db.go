@@ -98,6 +98,24 @@ func (mr *MockRepositoryMockRecorder) Delete(bucketName, model interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), bucketName, model)
 }
 
+// Apply mocks base method
+func (m *MockRepository) Apply(writes ...db.Write) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range writes {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Apply", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply
+func (mr *MockRepositoryMockRecorder) Apply(writes ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockRepository)(nil).Apply), writes...)
+}
+
 // Fetch mocks base method
 func (m *MockRepository) Fetch(bucketName, name string, model db.Model) (bool, error) {
 	m.ctrl.T.Helper()
@@ -128,6 +146,21 @@ func (mr *MockRepositoryMockRecorder) FetchAll(bucketName, modelFn interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAll", reflect.TypeOf((*MockRepository)(nil).FetchAll), bucketName, modelFn)
 }
 
+// FetchFrom mocks base method
+func (m *MockRepository) FetchFrom(bucketName, from string, modelFn func([]byte) (db.Model, error)) ([]db.Model, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchFrom", bucketName, from, modelFn)
+	ret0, _ := ret[0].([]db.Model)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchFrom indicates an expected call of FetchFrom
+func (mr *MockRepositoryMockRecorder) FetchFrom(bucketName, from, modelFn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchFrom", reflect.TypeOf((*MockRepository)(nil).FetchFrom), bucketName, from, modelFn)
+}
+
 // Close mocks base method
 func (m *MockRepository) Close() error {
 	m.ctrl.T.Helper()
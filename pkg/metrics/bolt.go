@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.etcd.io/bbolt"
+)
+
+// statsReporter represents the repository reporting bolt stats
+type statsReporter interface {
+	Stats() bbolt.Stats
+}
+
+var (
+	boltReadTx = prometheus.NewDesc(prometheus.BuildFQName(namespace, "bolt", "read_tx_total"),
+		"Number of started read transactions.", nil, nil)
+	boltOpenReadTx = prometheus.NewDesc(prometheus.BuildFQName(namespace, "bolt", "open_read_tx"),
+		"Number of currently open read transactions.", nil, nil)
+	boltWrites = prometheus.NewDesc(prometheus.BuildFQName(namespace, "bolt", "writes_total"),
+		"Number of writes performed.", nil, nil)
+	boltWriteSeconds = prometheus.NewDesc(prometheus.BuildFQName(namespace, "bolt", "write_seconds_total"),
+		"Seconds spent writing to disk.", nil, nil)
+	boltPagesAllocated = prometheus.NewDesc(prometheus.BuildFQName(namespace, "bolt", "pages_allocated_total"),
+		"Number of page allocations.", nil, nil)
+	boltFreePages = prometheus.NewDesc(prometheus.BuildFQName(namespace, "bolt", "free_pages"),
+		"Number of free pages on the freelist.", nil, nil)
+)
+
+// boltCollector collects the transaction stats of the global repository, if it is backed by bolt
+type boltCollector struct{}
+
+// Describe sends the descriptors of the bolt metrics
+func (boltCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{boltReadTx, boltOpenReadTx, boltWrites, boltWriteSeconds, boltPagesAllocated, boltFreePages} {
+		ch <- desc
+	}
+}
+
+// Collect sends the bolt metrics
+func (boltCollector) Collect(ch chan<- prometheus.Metric) {
+	reporter, ok := db.GlobalRepository().(statsReporter)
+	if !ok {
+		return
+	}
+	stats := reporter.Stats()
+	ch <- prometheus.MustNewConstMetric(boltReadTx, prometheus.CounterValue, float64(stats.TxN))
+	ch <- prometheus.MustNewConstMetric(boltOpenReadTx, prometheus.GaugeValue, float64(stats.OpenTxN))
+	ch <- prometheus.MustNewConstMetric(boltWrites, prometheus.CounterValue, float64(stats.TxStats.Write))
+	ch <- prometheus.MustNewConstMetric(boltWriteSeconds, prometheus.CounterValue, stats.TxStats.WriteTime.Seconds())
+	ch <- prometheus.MustNewConstMetric(boltPagesAllocated, prometheus.CounterValue, float64(stats.TxStats.PageCount))
+	ch <- prometheus.MustNewConstMetric(boltFreePages, prometheus.GaugeValue, float64(stats.FreePageN))
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+const namespace = "nightfury"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	// GamesStarted counts the games started by game name
+	GamesStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_started_total",
+		Help:      "Number of games started.",
+	}, []string{"game"})
+
+	// GamesCompleted counts the games completed by game name
+	GamesCompleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_completed_total",
+		Help:      "Number of games completed.",
+	}, []string{"game"})
+
+	// GamesFailed counts the games failed by game name
+	GamesFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_failed_total",
+		Help:      "Number of games failed.",
+	}, []string{"game"})
+
+	// GameDuration observes the seconds a game was played until it completed or failed, excluding pauses
+	GameDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "game_duration_seconds",
+		Help:      "Seconds a game was played until it completed or failed, excluding pauses.",
+		Buckets:   []float64{15, 30, 60, 120, 180, 300, 600, 900, 1800},
+	}, []string{"game", "status"})
+
+	// RequestDuration observes the latency of the http requests by route
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the http requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+func init() {
+	MustRegister(GamesStarted, GamesCompleted, GamesFailed, GameDuration, RequestDuration, boltCollector{})
+}
+
+// MustRegister registers the collectors to be exposed, panics if a collector cannot be registered
+func MustRegister(collectors ...prometheus.Collector) {
+	registry.MustRegister(collectors...)
+}
+
+// Handler returns the handler exposing the metrics in prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,291 @@
+package nightfury
+
+import (
+	"encoding/csv"
+	"github.com/boothgames/nightfury/pkg/db"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// GameAnalytics represents how often a game has been played and how hard it turned out to be
+type GameAnalytics struct {
+	Game           string  `json:"game"`
+	Attempts       int     `json:"attempts"`
+	Completed      int     `json:"completed"`
+	Failed         int     `json:"failed"`
+	CompletionRate float64 `json:"completionRate"`
+	FailureRate    float64 `json:"failureRate"`
+	MedianDuration float64 `json:"medianDurationSeconds"`
+	P90Duration    float64 `json:"p90DurationSeconds"`
+}
+
+// FunnelStep represents the clients which reached a game of the sequence and those which dropped off at it
+type FunnelStep struct {
+	Game        string  `json:"game"`
+	Reached     int     `json:"reached"`
+	Completed   int     `json:"completed"`
+	DroppedOff  int     `json:"droppedOff"`
+	DropOffRate float64 `json:"dropOffRate"`
+}
+
+// Analytics represents the analytics of the games, ordered by their sequence
+type Analytics struct {
+	Games  []GameAnalytics `json:"games"`
+	Funnel []FunnelStep    `json:"funnel"`
+}
+
+// NewAnalyticsFromRepo returns the analytics of the games from the history of every client
+func NewAnalyticsFromRepo(repo db.Repository) (Analytics, error) {
+	models, err := NewGamesFromRepo(repo)
+	if err != nil {
+		return Analytics{}, err
+	}
+	games := Games{}
+	all, _ := models.(map[string]interface{})
+	for _, model := range all {
+		if game, ok := model.(Game); ok {
+			games[game.Name] = game
+		}
+	}
+
+	models, err = NewClientsFromRepo(repo)
+	if err != nil {
+		return Analytics{}, err
+	}
+	var histories []History
+	all, _ = models.(map[string]interface{})
+	for _, model := range all {
+		if client, ok := model.(Client); ok {
+			history, err := NewHistoryFromRepoWithName(repo, client.Name)
+			if err != nil {
+				return Analytics{}, err
+			}
+			histories = append(histories, history)
+		}
+	}
+	return NewAnalytics(games, histories...), nil
+}
+
+// NewAnalytics returns the analytics of the games played in the histories. An attempt starts with a game
+// started, its duration excludes the time it was paused, and only completed attempts are timed.
+// Games no longer configured but found in the histories follow the sequence, sorted by name
+func NewAnalytics(games Games, histories ...History) Analytics {
+	played := map[string]*gameRecord{}
+	for _, history := range histories {
+		for name, record := range newGameRecords(history) {
+			total, ok := played[name]
+			if !ok {
+				total = &gameRecord{}
+				played[name] = total
+			}
+			total.add(record)
+		}
+	}
+
+	analytics := Analytics{Games: []GameAnalytics{}, Funnel: []FunnelStep{}}
+	for _, name := range analyticsOrder(games, played) {
+		record, ok := played[name]
+		if !ok {
+			record = &gameRecord{}
+		}
+		analytics.Games = append(analytics.Games, record.analytics(name))
+		analytics.Funnel = append(analytics.Funnel, record.funnelStep(name))
+	}
+	return analytics
+}
+
+// WriteCSV writes the analytics as csv, one row per game along with its funnel step
+func (a Analytics) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"game", "attempts", "completed", "failed", "completion_rate", "failure_rate",
+		"median_duration_seconds", "p90_duration_seconds", "reached", "dropped_off", "drop_off_rate"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i, game := range a.Games {
+		step := a.Funnel[i]
+		row := []string{game.Game, strconv.Itoa(game.Attempts), strconv.Itoa(game.Completed), strconv.Itoa(game.Failed),
+			formatFloat(game.CompletionRate), formatFloat(game.FailureRate), formatFloat(game.MedianDuration),
+			formatFloat(game.P90Duration), strconv.Itoa(step.Reached), strconv.Itoa(step.DroppedOff), formatFloat(step.DropOffRate)}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// gameRecord represents the attempts of a game, by one client or summed over every client
+type gameRecord struct {
+	attempts  int
+	completed int
+	failed    int
+	durations []float64
+
+	reached         int
+	reachedComplete int
+}
+
+func (r *gameRecord) add(other *gameRecord) {
+	r.attempts += other.attempts
+	r.completed += other.completed
+	r.failed += other.failed
+	r.durations = append(r.durations, other.durations...)
+	r.reached += other.reached
+	r.reachedComplete += other.reachedComplete
+}
+
+func (r *gameRecord) analytics(name string) GameAnalytics {
+	sort.Float64s(r.durations)
+	return GameAnalytics{
+		Game:           name,
+		Attempts:       r.attempts,
+		Completed:      r.completed,
+		Failed:         r.failed,
+		CompletionRate: rate(r.completed, r.attempts),
+		FailureRate:    rate(r.failed, r.attempts),
+		MedianDuration: percentile(r.durations, 0.5),
+		P90Duration:    percentile(r.durations, 0.9),
+	}
+}
+
+func (r *gameRecord) funnelStep(name string) FunnelStep {
+	droppedOff := r.reached - r.reachedComplete
+	return FunnelStep{
+		Game:        name,
+		Reached:     r.reached,
+		Completed:   r.reachedComplete,
+		DroppedOff:  droppedOff,
+		DropOffRate: rate(droppedOff, r.reached),
+	}
+}
+
+// newGameRecords returns the attempts of the games in the history of a client, the client counting once
+// in the funnel of every game it started and of every game it completed
+func newGameRecords(history History) map[string]*gameRecord {
+	records := map[string]*gameRecord{}
+	clocks := map[string]*gameClock{}
+	for _, event := range history.Events {
+		record, ok := records[event.Game]
+		if !ok {
+			record = &gameRecord{}
+		}
+		switch event.Type {
+		case GameStarted:
+			record.attempts++
+			record.reached = 1
+			clocks[event.Game] = startedClock(event.At)
+		case GamePaused:
+			if clock, ok := clocks[event.Game]; ok {
+				clock.pause(event.At)
+			}
+		case GameResumed:
+			if clock, ok := clocks[event.Game]; ok {
+				clock.resume(event.At)
+			}
+		case GameCompleted:
+			record.completed++
+			record.reachedComplete = 1
+			if clock, ok := clocks[event.Game]; ok && clock.running {
+				record.durations = append(record.durations, clock.until(event.At).Seconds())
+			}
+			delete(clocks, event.Game)
+		case GameFailed:
+			record.failed++
+			delete(clocks, event.Game)
+		case GameReset, GameRemoved:
+			delete(clocks, event.Game)
+		default:
+			continue
+		}
+		records[event.Game] = record
+	}
+	return records
+}
+
+// analyticsOrder returns the games in the order they are played, following their prerequisites and
+// branches and otherwise their names, followed by the games played which are no longer configured
+func analyticsOrder(games Games, played map[string]*gameRecord) []string {
+	order := Sequence(games)
+	var removed []string
+	for name := range played {
+		if _, ok := games[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return append(order, removed...)
+}
+
+// Sequence returns the names of the games in the order they are played, a game coming after its
+// prerequisites and the games branching into it, and games which can be played at the same point by name
+func Sequence(games Games) []string {
+	dependencies := map[string]int{}
+	dependents := map[string][]string{}
+	for name, game := range games {
+		dependencies[name] += 0
+		for _, next := range game.Branches {
+			if _, ok := games[next]; ok {
+				dependencies[next]++
+				dependents[name] = append(dependents[name], next)
+			}
+		}
+		for _, prerequisite := range game.Prerequisites {
+			if _, ok := games[prerequisite]; ok {
+				dependencies[name]++
+				dependents[prerequisite] = append(dependents[prerequisite], name)
+			}
+		}
+	}
+
+	sequence := make([]string, 0, len(games))
+	for len(dependencies) > 0 {
+		var ready []string
+		for name, count := range dependencies {
+			if count == 0 {
+				ready = append(ready, name)
+			}
+		}
+		if len(ready) == 0 {
+			// a cycle, which uploads reject, is played by name
+			for name := range dependencies {
+				ready = append(ready, name)
+			}
+		}
+		sort.Strings(ready)
+		next := ready[0]
+		sequence = append(sequence, next)
+		delete(dependencies, next)
+		for _, dependent := range dependents[next] {
+			if _, ok := dependencies[dependent]; ok {
+				dependencies[dependent]--
+			}
+		}
+	}
+	return sequence
+}
+
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+// percentile returns the nearest rank percentile of the sorted values, 0 if there are none
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,90 @@
+package nightfury_test
+
+import (
+	"bytes"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSequence(t *testing.T) {
+	t.Run("should order the games by name without a flow", func(t *testing.T) {
+		games := nightfury.Games{"snakes": {Name: "snakes"}, "ludo": {Name: "ludo"}, "seeker": {Name: "seeker"}}
+
+		assert.Equal(t, []string{"ludo", "seeker", "snakes"}, nightfury.Sequence(games))
+	})
+
+	t.Run("should order the games after their prerequisites and branching games", func(t *testing.T) {
+		games := nightfury.Games{
+			"ludo":   {Name: "ludo", Prerequisites: []string{"snakes"}},
+			"snakes": {Name: "snakes", Branches: []string{"seeker", "smile"}},
+			"seeker": {Name: "seeker"},
+			"smile":  {Name: "smile"},
+			"zebra":  {Name: "zebra"},
+		}
+
+		assert.Equal(t, []string{"snakes", "ludo", "seeker", "smile", "zebra"}, nightfury.Sequence(games))
+	})
+}
+
+func TestNewAnalytics(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	games := nightfury.Games{"snakes": {Name: "snakes"}, "seeker": {Name: "seeker", Prerequisites: []string{"snakes"}}}
+	first := nightfury.History{Client: "kiosk-1", Events: []nightfury.GameplayEvent{
+		{Type: nightfury.GameAdded, Game: "snakes", At: at},
+		{Type: nightfury.RunStarted, At: at},
+		{Type: nightfury.GameStarted, Game: "snakes", Status: nightfury.InProgress, At: at},
+		{Type: nightfury.GameFailed, Game: "snakes", Status: nightfury.Failed, At: at.Add(20 * time.Second)},
+		{Type: nightfury.GameReset, Game: "snakes", Status: nightfury.Ready, At: at.Add(30 * time.Second)},
+		{Type: nightfury.GameStarted, Game: "snakes", Status: nightfury.InProgress, At: at.Add(40 * time.Second)},
+		{Type: nightfury.GamePaused, Game: "snakes", Status: nightfury.Paused, At: at.Add(50 * time.Second)},
+		{Type: nightfury.GameResumed, Game: "snakes", Status: nightfury.InProgress, At: at.Add(110 * time.Second)},
+		{Type: nightfury.GameCompleted, Game: "snakes", Status: nightfury.Completed, At: at.Add(130 * time.Second)},
+		{Type: nightfury.GameStarted, Game: "seeker", Status: nightfury.InProgress, At: at.Add(130 * time.Second)},
+		{Type: nightfury.GameCompleted, Game: "seeker", Status: nightfury.Completed, At: at.Add(190 * time.Second)},
+	}}
+	second := nightfury.History{Client: "kiosk-2", Events: []nightfury.GameplayEvent{
+		{Type: nightfury.GameStarted, Game: "snakes", Status: nightfury.InProgress, At: at},
+		{Type: nightfury.GameCompleted, Game: "snakes", Status: nightfury.Completed, At: at.Add(10 * time.Second)},
+		{Type: nightfury.GameStarted, Game: "seeker", Status: nightfury.InProgress, At: at.Add(10 * time.Second)},
+		{Type: nightfury.GameFailed, Game: "seeker", Status: nightfury.Failed, At: at.Add(20 * time.Second)},
+		{Type: nightfury.GameStarted, Game: "ludo", Status: nightfury.InProgress, At: at.Add(30 * time.Second)},
+	}}
+
+	actual := nightfury.NewAnalytics(games, first, second)
+
+	expected := nightfury.Analytics{
+		Games: []nightfury.GameAnalytics{
+			{Game: "snakes", Attempts: 3, Completed: 2, Failed: 1, CompletionRate: 2.0 / 3, FailureRate: 1.0 / 3, MedianDuration: 10, P90Duration: 30},
+			{Game: "seeker", Attempts: 2, Completed: 1, Failed: 1, CompletionRate: 0.5, FailureRate: 0.5, MedianDuration: 60, P90Duration: 60},
+			{Game: "ludo", Attempts: 1},
+		},
+		Funnel: []nightfury.FunnelStep{
+			{Game: "snakes", Reached: 2, Completed: 2},
+			{Game: "seeker", Reached: 2, Completed: 1, DroppedOff: 1, DropOffRate: 0.5},
+			{Game: "ludo", Reached: 1, DroppedOff: 1, DropOffRate: 1},
+		},
+	}
+	assert.Equal(t, expected, actual)
+
+	t.Run("should write the analytics as csv", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		err := actual.WriteCSV(out)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `game,attempts,completed,failed,completion_rate,failure_rate,median_duration_seconds,p90_duration_seconds,reached,dropped_off,drop_off_rate
+snakes,3,2,1,0.6666666666666666,0.3333333333333333,10,30,2,0,0
+seeker,2,1,1,0.5,0.5,60,60,2,1,0.5
+ludo,1,0,0,0,0,0,0,1,1,1
+`, out.String())
+	})
+}
+
+func TestNewAnalyticsWithoutHistory(t *testing.T) {
+	actual := nightfury.NewAnalytics(nightfury.Games{"snakes": {Name: "snakes"}})
+
+	assert.Equal(t, []nightfury.GameAnalytics{{Game: "snakes"}}, actual.Games)
+	assert.Equal(t, []nightfury.FunnelStep{{Game: "snakes"}}, actual.Funnel)
+}
@@ -0,0 +1,130 @@
+package nightfury
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"sync/atomic"
+	"time"
+)
+
+var auditBucketName = "audit"
+
+// sequence orders the entries and events recorded within the same nanosecond
+var sequence uint64
+
+// Types of the actors making changes
+const (
+	APIActor          = "api"
+	ClientSocketActor = "client-socket"
+	GameSocketActor   = "game-socket"
+	ServerActor       = "server"
+)
+
+// Actor represents who made a change, the rest api is identified by the address and request id of the caller
+type Actor struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// AuditEntry represents a change made to games, hints or clients along with the values before and after it
+type AuditEntry struct {
+	Sequence string          `json:"id"`
+	At       time.Time       `json:"at"`
+	Actor    Actor           `json:"actor"`
+	Action   string          `json:"action"`
+	Entity   string          `json:"entity"`
+	Name     string          `json:"name"`
+	Client   string          `json:"client,omitempty"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditFilter represents the entries to return, empty fields match every entry
+type AuditFilter struct {
+	Client string
+	Since  time.Time
+}
+
+// NewAuditEntry returns the entry of the change, before or after are nil on creation or deletion
+func NewAuditEntry(at time.Time, actor Actor, action, entity, name string, before, after interface{}) (AuditEntry, error) {
+	entry := AuditEntry{
+		Sequence: sequenceKey(at),
+		At:       at,
+		Actor:    actor,
+		Action:   action,
+		Entity:   entity,
+		Name:     name,
+	}
+	var err error
+	if entry.Before, err = rawValue(before); err != nil {
+		return entry, err
+	}
+	entry.After, err = rawValue(after)
+	return entry, err
+}
+
+// sequenceKey returns a key sorting in the order the keys were made
+func sequenceKey(at time.Time) string {
+	return fmt.Sprintf("%019d-%06d", at.UnixNano(), atomic.AddUint64(&sequence, 1)%1000000)
+}
+
+func rawValue(value interface{}) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+// NewAuditEntriesFromRepo returns the entries matching the filter from db, oldest first.
+// The entries are keyed by their sequence, so they are read from the first one recorded since the filter
+func NewAuditEntriesFromRepo(repo db.Repository, filter AuditFilter) ([]AuditEntry, error) {
+	models, err := repo.FetchFrom(auditBucketName, filter.from(), func(data []byte) (model db.Model, e error) {
+		entry := AuditEntry{}
+		err := json.Unmarshal(data, &entry)
+		return entry, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := []AuditEntry{}
+	for _, model := range models {
+		entry, ok := model.(AuditEntry)
+		if ok && filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// from returns the first sequence key the entries since the filter can have
+func (f AuditFilter) from() string {
+	if f.Since.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%019d", f.Since.UnixNano())
+}
+
+func (f AuditFilter) matches(entry AuditEntry) bool {
+	if f.Client != "" && entry.Client != f.Client {
+		return false
+	}
+	return f.Since.IsZero() || !entry.At.Before(f.Since)
+}
+
+// ForClient returns the entry concerning the client
+func (e AuditEntry) ForClient(client string) AuditEntry {
+	e.Client = client
+	return e
+}
+
+// ID returns the identifiable name for audit entry
+func (e AuditEntry) ID() string {
+	return e.Sequence
+}
+
+// Save appends the entry to the audit log in db
+func (e AuditEntry) Save(repo db.Repository) error {
+	return repo.Save(auditBucketName, e)
+}
@@ -0,0 +1,100 @@
+package nightfury_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/internal/mocks/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewAuditEntry(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	actor := nightfury.Actor{Type: nightfury.APIActor, ID: "10.0.0.12", RequestID: "req-42"}
+
+	t.Run("should keep the values before and after the change", func(t *testing.T) {
+		before := nightfury.Game{Name: "snakes", Instruction: "eat", Type: "web"}
+		after := nightfury.Game{Name: "snakes", Instruction: "eat apples", Type: "web"}
+
+		entry, err := nightfury.NewAuditEntry(at, actor, "update", "game", "snakes", before, after)
+
+		assert.NoError(t, err)
+		actual := nightfury.Game{}
+		_ = json.Unmarshal(entry.After, &actual)
+		assert.Equal(t, after, actual)
+		assert.Contains(t, string(entry.Before), `"instruction":"eat"`)
+		assert.Equal(t, actor, entry.Actor)
+	})
+
+	t.Run("should leave out the value before a creation", func(t *testing.T) {
+		entry, _ := nightfury.NewAuditEntry(at, actor, "create", "game", "snakes", nil, nightfury.Game{Name: "snakes"})
+
+		assert.Nil(t, entry.Before)
+		assert.NotNil(t, entry.After)
+	})
+
+	t.Run("should order entries recorded at the same time", func(t *testing.T) {
+		first, _ := nightfury.NewAuditEntry(at, actor, "create", "game", "snakes", nil, nil)
+		second, _ := nightfury.NewAuditEntry(at, actor, "create", "game", "smile", nil, nil)
+
+		assert.True(t, first.ID() < second.ID())
+	})
+}
+
+func TestNewAuditEntriesFromRepo(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	first, _ := nightfury.NewAuditEntry(at, nightfury.Actor{}, "start", "client", "kiosk-1", nil, nil)
+	second, _ := nightfury.NewAuditEntry(at.Add(time.Minute), nightfury.Actor{}, "create", "game", "snakes", nil, nil)
+	third, _ := nightfury.NewAuditEntry(at.Add(2*time.Minute), nightfury.Actor{}, "reset", "client", "kiosk-1", nil, nil)
+	entries := []nightfury.AuditEntry{first.ForClient("kiosk-1"), second, third.ForClient("kiosk-1")}
+
+	fetch := func(repository *mocks.MockRepository, from string) {
+		repository.EXPECT().FetchFrom("audit", from, gomock.Any()).DoAndReturn(
+			func(bucketName string, from string, modelFn func(data []byte) (db.Model, error)) ([]db.Model, error) {
+				var models []db.Model
+				for _, entry := range entries {
+					if entry.ID() >= from {
+						data, _ := json.Marshal(entry)
+						model, _ := modelFn(data)
+						models = append(models, model)
+					}
+				}
+				return models, nil
+			})
+	}
+
+	t.Run("should return the entries oldest first", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		fetch(repository, "")
+
+		actual, err := nightfury.NewAuditEntriesFromRepo(repository, nightfury.AuditFilter{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{first.ID(), second.ID(), third.ID()}, auditIDs(actual))
+	})
+
+	t.Run("should return the entries of the client since the given time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		fetch(repository, "1569924001000000000")
+
+		actual, err := nightfury.NewAuditEntriesFromRepo(repository, nightfury.AuditFilter{Client: "kiosk-1", Since: at.Add(time.Second)})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{third.ID()}, auditIDs(actual))
+	})
+}
+
+func auditIDs(entries []nightfury.AuditEntry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID())
+	}
+	return ids
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/boothgames/nightfury/pkg/db"
+	"strings"
+	"time"
 )
 
 var clientsBucketName = "clients"
@@ -13,6 +15,49 @@ type Client struct {
 	Name         string       `json:"name"`
 	Available    bool         `json:"available"`
 	GameStatuses GameStatuses `json:"gameStatuses"`
+	StartedAt    time.Time    `json:"startedAt"`
+	LastSeen     time.Time    `json:"lastSeen"`
+	Team         string       `json:"team,omitempty"`
+	Race         string       `json:"race,omitempty"`
+	Games        []string     `json:"games,omitempty"`
+	Profile      Profile      `json:"profile"`
+	Player       string       `json:"player,omitempty"`
+}
+
+// Profile represents where the client is placed on the booth floor and the device it runs on.
+// Location, device type and tags are edited by admins, the rest is captured when the client connects
+type Profile struct {
+	Location       string    `json:"location,omitempty"`
+	DeviceType     string    `json:"deviceType,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
+	IPAddress      string    `json:"ipAddress,omitempty"`
+	UserAgent      string    `json:"userAgent,omitempty"`
+	Version        string    `json:"version,omitempty"`
+	ConnectedSince time.Time `json:"connectedSince"`
+}
+
+// Device represents the device details captured from the request opening the client socket
+type Device struct {
+	Type      string
+	IPAddress string
+	UserAgent string
+	Version   string
+}
+
+// Edit returns the profile with the admin editable fields of the given profile
+func (p Profile) Edit(edited Profile) Profile {
+	p.Location = edited.Location
+	p.DeviceType = edited.DeviceType
+	p.Tags = edited.Tags
+	return p
+}
+
+// MissingGames represents the configured games whose sockets are not connected
+type MissingGames []string
+
+// Error returns the error string
+func (m MissingGames) Error() string {
+	return fmt.Sprintf("games %v are not connected", strings.Join(m, ", "))
 }
 
 // Clients represents the collection of Client
@@ -20,9 +65,8 @@ type Clients map[string]Client
 
 // Delete deletes all the client information from db
 func (c Clients) Delete(repo db.Repository) error {
-	repository := db.DefaultRepository()
 	for _, client := range c {
-		if err := client.Delete(repository); err != nil {
+		if err := client.Delete(repo); err != nil {
 			return fmt.Errorf("delete failed for client %v, error: %v", client.Name, err)
 		}
 	}
@@ -70,9 +114,52 @@ func (c Client) ID() string {
 	return c.Name
 }
 
-// Add attaches a game to the client
+// Add attaches a game to the client along with its prerequisites and branches
 func (c Client) Add(game Game) {
-	c.GameStatuses[game.Name] = GameStatus{Name: game.Name, Status: Ready}
+	c.GameStatuses[game.Name] = GameStatus{
+		Name:          game.Name,
+		Status:        Ready,
+		Prerequisites: game.Prerequisites,
+		Branches:      game.Branches,
+	}
+}
+
+// Assign configures the games played by the client, their statuses are reset
+// and returns error if a game is in progress
+func (c Client) Assign(games ...Game) (Client, error) {
+	if c.GameStatuses.IsAnyGameInProgress() {
+		return c, fmt.Errorf("cannot change games of client %v while a game is in progress", c.Name)
+	}
+	c.Games = make([]string, 0, len(games))
+	c.GameStatuses = GameStatuses{}
+	c.StartedAt = time.Time{}
+	for _, game := range games {
+		c.Games = append(c.Games, game.Name)
+		c.Add(game)
+	}
+	return c, nil
+}
+
+// IsConfigured checks if the games of the client have been configured
+func (c Client) IsConfigured() bool {
+	return len(c.Games) > 0
+}
+
+// Plays checks if the game is part of the run of the client, any game is
+// part of the run until the games are configured
+func (c Client) Plays(name string) bool {
+	return !c.IsConfigured() || contains(c.Games, name)
+}
+
+// Missing returns the configured games which are not connected
+func (c Client) Missing(connected ...string) MissingGames {
+	var missing MissingGames
+	for _, name := range c.Games {
+		if !contains(connected, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
 }
 
 // Remove removes the game from the client
@@ -86,12 +173,36 @@ func (c Client) Connected() Client {
 	return c
 }
 
+// ConnectedFrom records the device the client connected from, the device type set by admins is kept
+// unless the device reports one
+func (c Client) ConnectedFrom(device Device, at time.Time) Client {
+	if device.Type != "" {
+		c.Profile.DeviceType = device.Type
+	}
+	c.Profile.IPAddress = device.IPAddress
+	c.Profile.UserAgent = device.UserAgent
+	c.Profile.Version = device.Version
+	c.Profile.ConnectedSince = at
+	return c
+}
+
 // Disconnected marks the client as unavailable
 func (c Client) Disconnected() Client {
 	c.Available = false
 	return c
 }
 
+// Seen records when the client was last heard from
+func (c Client) Seen(at time.Time) Client {
+	c.LastSeen = at
+	return c
+}
+
+// IsStale returns true if an available client has not been heard from within timeout
+func (c Client) IsStale(now time.Time, timeout time.Duration) bool {
+	return c.Available && now.Sub(c.LastSeen) > timeout
+}
+
 // Status represents Client status based on game status
 func (c Client) Status() Status {
 	statusCount := map[Status]int{}
@@ -102,43 +213,127 @@ func (c Client) Status() Status {
 	if statusCount[Ready] == gamesCount {
 		return Ready
 	}
-	if statusCount[Completed] == gamesCount {
+	if statusCount[Completed]+statusCount[Skipped] == gamesCount {
 		return Completed
 	}
 	if statusCount[Failed] >= 1 {
 		return Failed
 	}
+	if statusCount[Paused] >= 1 {
+		return Paused
+	}
 	return InProgress
 }
 
-// Save saves the client information to db
+// CurrentGame returns the name of the game in progress or paused, false if there is none
+func (c Client) CurrentGame() (string, bool) {
+	if name, ok := c.GameStatuses.InProgressGame(); ok {
+		return name, true
+	}
+	return c.GameStatuses.PausedGame()
+}
+
+// Save saves the client information to db along with the gameplay events changing its stored game statuses,
+// the game statuses and the events of a team member are shared with its team and the other members in a single transaction
 func (c Client) Save(repo db.Repository) error {
+	stored, err := NewClientFromRepoWithName(repo, c.Name)
+	if _, ok := err.(db.EntryNotFound); !ok && err != nil {
+		return err
+	}
+	events := stored.changesTo(c, time.Now())
+	writes := []db.Write{{Bucket: clientsBucketName, Model: c}}
+	members := []string{c.Name}
+	if c.Team != "" {
+		team, err := NewTeamFromRepoWithName(repo, c.Team)
+		if err != nil {
+			return err
+		}
+		shared, err := team.share(repo, c)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, shared...)
+		members = team.Members
+	}
+	if len(writes) == 1 && len(events) == 0 {
+		return repo.Save(clientsBucketName, c)
+	}
+	return repo.Apply(append(writes, historyWrites(events, members...)...)...)
+}
+
+// changesTo returns the gameplay events turning the stored client into the given one, starting
+// a run when the given one has been started since
+func (c Client) changesTo(updated Client, at time.Time) []GameplayEvent {
+	events := Changes(c.GameStatuses, updated.GameStatuses, at)
+	if !updated.StartedAt.IsZero() && !updated.StartedAt.Equal(c.StartedAt) {
+		events = append([]GameplayEvent{NewRunStarted(updated.StartedAt)}, events...)
+	}
+	return events
+}
+
+// SavePresence saves the client alone, for changes to its availability or last seen time
+// which are not shared with its team
+func (c Client) SavePresence(repo db.Repository) error {
 	return repo.Save(clientsBucketName, c)
 }
 
+// Members returns the names of the clients sharing the game statuses of the client
+func (c Client) Members(repo db.Repository) ([]string, error) {
+	if c.Team == "" {
+		return []string{c.Name}, nil
+	}
+	team, err := NewTeamFromRepoWithName(repo, c.Team)
+	if err != nil {
+		return []string{c.Name}, err
+	}
+	return team.Members, nil
+}
+
 // Delete deletes the client information to db
 func (c Client) Delete(repo db.Repository) error {
 	return repo.Delete(clientsBucketName, c)
 }
 
-// Start starts the first ready game, returns error if game is already started
-func (c Client) Start() (Game, error) {
-	if c.Status() == Ready {
-		return c.startNextGame()
+// Start starts the first ready game and times the run from now, returns error if game is already started,
+// if registration is required and no player has registered, or if any configured game is not among the connected games
+func (c Client) Start(repo db.Repository, connected ...string) (Game, error) {
+	game, err := c.Startable(repo, connected...)
+	if err != nil {
+		return game, err
+	}
+	c.StartedAt = time.Now()
+	return c.start(repo, game)
+}
+
+// Startable returns the game Start would start, or the error Start would fail with, without starting it
+func (c Client) Startable(repo db.Repository, connected ...string) (Game, error) {
+	if c.Status() != Ready {
+		return Game{}, fmt.Errorf("game already started")
+	}
+	if err := c.Registered(); err != nil {
+		return Game{}, err
+	}
+	if missing := c.Missing(connected...); len(missing) > 0 {
+		return Game{}, missing
 	}
-	return Game{}, fmt.Errorf("game already started")
+	game, err := c.GameStatuses.ReadyGame(repo)
+	if err != nil {
+		return game, err
+	}
+	_, err = c.GameStatuses[game.Name].InProgress()
+	return game, err
 }
 
-// HasNext checks if there is any game to play
+// HasNext checks if there is any game available to play
 func (c Client) HasNext() bool {
-	if c.Status() != Failed && c.GameStatuses.HasReadyGames() {
+	if c.Status() != Failed && len(c.GameStatuses.Available()) > 0 {
 		return true
 	}
 	return false
 }
 
 // Next returns next ready game
-func (c Client) Next() (Game, error) {
+func (c Client) Next(repo db.Repository) (Game, error) {
 	if c.Status() == Ready {
 		return Game{}, fmt.Errorf("game not yet started")
 	}
@@ -153,54 +348,140 @@ func (c Client) Next() (Game, error) {
 	if c.GameStatuses.IsAnyGameInProgress() {
 		return Game{}, fmt.Errorf("game already in progress")
 	}
-	return c.startNextGame()
+	return c.startNextGame(repo)
 }
 
-func (c Client) startNextGame() (Game, error) {
-	game, err := c.GameStatuses.ReadyGame()
+// Choose starts the chosen branch and skips the other branches, returns error if the game is not a choice
+func (c Client) Choose(repo db.Repository, name string) (Game, error) {
+	if c.Status() == Failed {
+		return Game{}, fmt.Errorf("game failed")
+	}
+	if c.GameStatuses.IsAnyGameInProgress() {
+		return Game{}, fmt.Errorf("game already in progress")
+	}
+	if !contains(c.GameStatuses.Choices(), name) {
+		return Game{}, fmt.Errorf("game %v is not one of the choices", name)
+	}
+	game, err := NewGameFromRepoWithName(repo, name)
 	if err != nil {
 		return game, err
 	}
+	return c.start(repo, game)
+}
 
-	repository := db.DefaultRepository()
+func (c Client) startNextGame(repo db.Repository) (Game, error) {
+	game, err := c.GameStatuses.ReadyGame(repo)
+	if err != nil {
+		return game, err
+	}
+	return c.start(repo, game)
+}
+
+func (c Client) start(repo db.Repository, game Game) (Game, error) {
 	gameStatus, err := c.GameStatuses[game.Name].InProgress()
 	if err != nil {
 		return game, err
 	}
 	c.GameStatuses[game.Name] = gameStatus
-	err = c.Save(repository)
+	c.GameStatuses.skipSiblings(game.Name)
+	err = c.Save(repo)
 	return game, err
 }
 
 // CompleteGame completes a given game
-func (c Client) CompleteGame(game Game) error {
-	repository := db.DefaultRepository()
+func (c Client) CompleteGame(repo db.Repository, game Game) error {
 	gameStatus, err := c.GameStatuses[game.Name].Completed()
 	if err != nil {
 		return err
 	}
 	c.GameStatuses[game.Name] = gameStatus
-	err = c.Save(repository)
+	err = c.Save(repo)
 	return err
 }
 
+// UpdateProgress records the progress of a given game
+func (c Client) UpdateProgress(repo db.Repository, game Game, progress Progress) error {
+	gameStatus, err := c.GameStatuses[game.Name].WithProgress(progress)
+	if err != nil {
+		return err
+	}
+	c.GameStatuses[game.Name] = gameStatus
+	return c.Save(repo)
+}
+
+// Pause pauses the game in progress, returns error if no game is in progress
+func (c Client) Pause(repo db.Repository) (Game, error) {
+	name, ok := c.GameStatuses.InProgressGame()
+	if !ok {
+		return Game{}, fmt.Errorf("no game in progress to pause")
+	}
+	return c.transition(repo, name, GameStatus.Paused)
+}
+
+// Resume resumes the paused game, returns error if no game is paused
+func (c Client) Resume(repo db.Repository) (Game, error) {
+	name, ok := c.GameStatuses.PausedGame()
+	if !ok {
+		return Game{}, fmt.Errorf("no paused game to resume")
+	}
+	return c.transition(repo, name, GameStatus.Resumed)
+}
+
+func (c Client) transition(repo db.Repository, name string, transitionFn func(GameStatus) (GameStatus, error)) (Game, error) {
+	game, err := NewGameFromRepoWithName(repo, name)
+	if _, ok := err.(db.EntryNotFound); ok {
+		game, err = Game{Name: name}, nil
+	}
+	if err != nil {
+		return game, err
+	}
+	gameStatus, err := transitionFn(c.GameStatuses[name])
+	if err != nil {
+		return game, err
+	}
+	c.GameStatuses[name] = gameStatus
+	return game, c.Save(repo)
+}
+
 // FailGame completes a given game
-func (c Client) FailGame(game Game) error {
-	repository := db.DefaultRepository()
+func (c Client) FailGame(repo db.Repository, game Game) error {
 	gameStatus, err := c.GameStatuses[game.Name].Failed()
 	if err != nil {
 		return err
 	}
 	c.GameStatuses[game.Name] = gameStatus
-	err = c.Save(repository)
+	err = c.Save(repo)
 	return err
 }
 
-// Reset resets state of all games
-func (c Client) Reset() error {
-	repository := db.DefaultRepository()
-	for name := range c.GameStatuses {
-		c.GameStatuses[name] = GameStatus{Name: name, Status: Ready}
+// Registered returns error if registration is required and no player has registered on the client
+func (c Client) Registered() error {
+	if registrationRequired && c.Player == "" {
+		return Unregistered(fmt.Sprintf("a player has to register on client %v before starting", c.Name))
+	}
+	return nil
+}
+
+// Register returns the client played by the player, refused once its games have started
+func (c Client) Register(player Player) (Client, error) {
+	if c.Status() != Ready {
+		return c, fmt.Errorf("cannot register on client %v once its games have started", c.Name)
+	}
+	c.Player = player.ID()
+	return c, nil
+}
+
+// Reset resets state of all games, and unregisters the player for the next one to register
+func (c Client) Reset(repo db.Repository) error {
+	c.Player = ""
+	c.StartedAt = time.Time{}
+	for name, gameStatus := range c.GameStatuses {
+		c.GameStatuses[name] = GameStatus{
+			Name:          name,
+			Status:        Ready,
+			Prerequisites: gameStatus.Prerequisites,
+			Branches:      gameStatus.Branches,
+		}
 	}
-	return c.Save(repository)
+	return c.Save(repo)
 }
@@ -9,9 +9,49 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
+	"reflect"
 	"testing"
+	"time"
 )
 
+// appliedWrites matches the writes of a transaction, the gameplay events are compared without their sequence and time
+type appliedWrites []db.Write
+
+func (w appliedWrites) Matches(x interface{}) bool {
+	writes, ok := x.([]db.Write)
+	if !ok || len(writes) != len(w) {
+		return false
+	}
+	for i, write := range writes {
+		if event, ok := write.Model.(nightfury.GameplayEvent); ok {
+			event.Sequence, event.At = "", time.Time{}
+			write.Model = event
+		}
+		if !reflect.DeepEqual(write, w[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w appliedWrites) String() string {
+	return fmt.Sprintf("applies %v", []db.Write(w))
+}
+
+func historyWrite(client string, event nightfury.GameplayEvent) db.Write {
+	return db.Write{Bucket: "history/" + client, Model: event}
+}
+
+// expectStoredClient stubs fetching the client as stored before it is changed
+func expectStoredClient(repository *mocks.MockRepository, client nightfury.Client) {
+	client.GameStatuses = client.GameStatuses.Copy()
+	repository.EXPECT().Fetch("clients", client.Name, gomock.Any()).DoAndReturn(
+		func(bucketName string, name string, model db.Model) (bool, error) {
+			*model.(*nightfury.Client) = client
+			return true, nil
+		})
+}
+
 func TestClientAdd(t *testing.T) {
 	t.Run("should be able to add game", func(t *testing.T) {
 		client := nightfury.NewClient("test", false)
@@ -94,6 +134,39 @@ func TestClientDisConnected(t *testing.T) {
 	})
 }
 
+func TestClientSeen(t *testing.T) {
+	t.Run("should record last seen", func(t *testing.T) {
+		now := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+		client := nightfury.Client{Name: "client"}
+		actual := client.Seen(now)
+		expected := nightfury.Client{Name: "client", LastSeen: now}
+
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestClientIsStale(t *testing.T) {
+	now := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should be stale when not seen within timeout", func(t *testing.T) {
+		client := nightfury.Client{Name: "client", Available: true, LastSeen: now.Add(-time.Minute)}
+
+		assert.True(t, client.IsStale(now, 30*time.Second))
+	})
+
+	t.Run("should not be stale when seen within timeout", func(t *testing.T) {
+		client := nightfury.Client{Name: "client", Available: true, LastSeen: now.Add(-10 * time.Second)}
+
+		assert.False(t, client.IsStale(now, 30*time.Second))
+	})
+
+	t.Run("should not be stale when already unavailable", func(t *testing.T) {
+		client := nightfury.Client{Name: "client", Available: false, LastSeen: now.Add(-time.Minute)}
+
+		assert.False(t, client.IsStale(now, 30*time.Second))
+	})
+}
+
 func TestClientSave(t *testing.T) {
 	t.Run("should be able to save client", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -101,6 +174,7 @@ func TestClientSave(t *testing.T) {
 
 		repository := mocks.NewMockRepository(ctrl)
 		client := nightfury.Client{Name: "client", Available: true}
+		repository.EXPECT().Fetch("clients", "client", gomock.Any()).Return(false, nil)
 		repository.EXPECT().Save("clients", client)
 
 		err := client.Save(repository)
@@ -114,6 +188,7 @@ func TestClientSave(t *testing.T) {
 
 		repository := mocks.NewMockRepository(ctrl)
 		client := nightfury.Client{Name: "client", Available: true}
+		repository.EXPECT().Fetch("clients", "client", gomock.Any()).Return(false, nil)
 		repository.EXPECT().Save("clients", client).Return(fmt.Errorf("unable to save"))
 
 		err := client.Save(repository)
@@ -122,6 +197,56 @@ func TestClientSave(t *testing.T) {
 			assert.Equal(t, "unable to save", err.Error())
 		}
 	})
+
+	t.Run("should share game statuses, the start of the run and gameplay events with team members", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		statuses := nightfury.GameStatuses{"ludo": {Name: "ludo", Status: nightfury.InProgress}}
+		startedAt := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+		client := nightfury.Client{Name: "kiosk-1", Team: "red", GameStatuses: statuses, StartedAt: startedAt}
+		expectStoredClient(repository, nightfury.Client{Name: "kiosk-1", Team: "red", GameStatuses: nightfury.GameStatuses{
+			"ludo": {Name: "ludo", Status: nightfury.Ready},
+		}})
+		repository.EXPECT().Fetch("teams", "red", gomock.Any()).DoAndReturn(
+			func(bucketName string, name string, model db.Model) (bool, error) {
+				*model.(*nightfury.Team) = nightfury.NewTeam("red", "kiosk-1", "kiosk-2")
+				return true, nil
+			})
+		repository.EXPECT().Fetch("clients", "kiosk-2", gomock.Any()).DoAndReturn(
+			func(bucketName string, name string, model db.Model) (bool, error) {
+				*model.(*nightfury.Client) = nightfury.Client{Name: "kiosk-2", Team: "red"}
+				return true, nil
+			})
+		started := nightfury.GameplayEvent{Type: nightfury.GameStarted, Game: "ludo", Status: nightfury.InProgress}
+		repository.EXPECT().Apply(appliedWrites{
+			db.Write{Bucket: "clients", Model: client},
+			db.Write{Bucket: "teams", Model: nightfury.Team{Name: "red", Members: []string{"kiosk-1", "kiosk-2"}, GameStatuses: statuses, StartedAt: startedAt}},
+			db.Write{Bucket: "clients", Model: nightfury.Client{Name: "kiosk-2", Team: "red", GameStatuses: statuses, StartedAt: startedAt}},
+			historyWrite("kiosk-1", nightfury.GameplayEvent{Type: nightfury.RunStarted}),
+			historyWrite("kiosk-1", started),
+			historyWrite("kiosk-2", nightfury.GameplayEvent{Type: nightfury.RunStarted}),
+			historyWrite("kiosk-2", started),
+		})
+
+		err := client.Save(repository)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestClientSavePresence(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repository := mocks.NewMockRepository(ctrl)
+	client := nightfury.Client{Name: "kiosk-1", Team: "red", Available: true}
+	repository.EXPECT().Save("clients", client)
+
+	err := client.SavePresence(repository)
+
+	assert.NoError(t, err)
 }
 
 func TestClientDelete(t *testing.T) {
@@ -310,6 +435,17 @@ func TestClientStatus(t *testing.T) {
 			},
 			status: nightfury.InProgress,
 		},
+		{
+			name: "should return Paused as status when a game is paused",
+			client: nightfury.Client{
+				GameStatuses: nightfury.GameStatuses{
+					"tic-tac-toe":      {Status: nightfury.Completed},
+					"ludo":             {Status: nightfury.Paused},
+					"snake-and-ladder": {Status: nightfury.Ready},
+				},
+			},
+			status: nightfury.Paused,
+		},
 		{
 			name: "should return InProgress as status when other games are completed",
 			client: nightfury.Client{
@@ -344,12 +480,8 @@ func TestClientStart(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
 		mockRepository.EXPECT().Fetch("games", gomock.Any(), gomock.Any()).DoAndReturn(
 			func(bucketName string, name string, model db.Model) (bool, error) {
@@ -358,9 +490,10 @@ func TestClientStart(t *testing.T) {
 				}
 				return false, nil
 			})
-		mockRepository.EXPECT().Save("clients", gomock.Any())
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(gomock.Any())
 
-		_, err := client.Start()
+		_, err := client.Start(mockRepository)
 		assert.NoError(t, err)
 	})
 
@@ -375,19 +508,59 @@ func TestClientStart(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
-		_, err := client.Start()
+		_, err := client.Start(mockRepository)
 		assert.Error(t, err)
 		assert.Equal(t, "game already started", err.Error())
 	})
 }
 
+func TestClientStartWithAssignedGames(t *testing.T) {
+	t.Run("should not start until every assigned game is connected", func(t *testing.T) {
+		client := nightfury.Client{
+			Games: []string{"ludo", "tic-tac-toe"},
+			GameStatuses: nightfury.GameStatuses{
+				"tic-tac-toe": {Status: nightfury.Ready},
+				"ludo":        {Status: nightfury.Ready},
+			},
+		}
+
+		_, err := client.Start(nil, "ludo")
+
+		assert.Equal(t, nightfury.MissingGames{"tic-tac-toe"}, err)
+		assert.Equal(t, "games tic-tac-toe are not connected", err.Error())
+	})
+}
+
+func TestClientAssign(t *testing.T) {
+	t.Run("should assign games along with their flow", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "ludo", Status: nightfury.Completed})
+
+		actual, err := client.Assign(nightfury.Game{Name: "snakes", Branches: []string{"seeker"}}, nightfury.Game{Name: "seeker"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"snakes", "seeker"}, actual.Games)
+		assert.Equal(t, nightfury.GameStatuses{
+			"snakes": {Name: "snakes", Status: nightfury.Ready, Branches: []string{"seeker"}},
+			"seeker": {Name: "seeker", Status: nightfury.Ready},
+		}, actual.GameStatuses)
+		assert.True(t, actual.Plays("seeker"))
+		assert.False(t, actual.Plays("ludo"))
+	})
+
+	t.Run("should not assign games while a game is in progress", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "ludo", Status: nightfury.InProgress})
+
+		_, err := client.Assign(nightfury.Game{Name: "snakes"})
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "cannot change games of client kiosk-1 while a game is in progress", err.Error())
+		}
+	})
+}
+
 func TestClientNext(t *testing.T) {
 	t.Run("should return the next game", func(t *testing.T) {
 		client := nightfury.Client{
@@ -400,12 +573,8 @@ func TestClientNext(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
 		mockRepository.EXPECT().Fetch("games", gomock.Any(), gomock.Any()).DoAndReturn(
 			func(bucketName string, name string, model db.Model) (bool, error) {
@@ -414,9 +583,10 @@ func TestClientNext(t *testing.T) {
 				}
 				return false, nil
 			})
-		mockRepository.EXPECT().Save("clients", client)
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(gomock.Any())
 
-		_, err := client.Next()
+		_, err := client.Next(mockRepository)
 
 		assert.NoError(t, err)
 	})
@@ -430,7 +600,7 @@ func TestClientNext(t *testing.T) {
 			},
 		}
 
-		_, err := client.Next()
+		_, err := client.Next(nil)
 
 		assert.Error(t, err)
 		assert.Equal(t, "game already in progress", err.Error())
@@ -447,12 +617,8 @@ func TestClientNext(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
 		mockRepository.EXPECT().Fetch("games", gomock.Any(), gomock.Any()).DoAndReturn(
 			func(bucketName string, name string, model db.Model) (bool, error) {
@@ -461,9 +627,10 @@ func TestClientNext(t *testing.T) {
 				}
 				return false, nil
 			})
-		mockRepository.EXPECT().Save("clients", client).Return(fmt.Errorf("unable to save"))
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(gomock.Any()).Return(fmt.Errorf("unable to save"))
 
-		_, err := client.Next()
+		_, err := client.Next(mockRepository)
 
 		assert.Error(t, err)
 		assert.Equal(t, "unable to save", err.Error())
@@ -480,14 +647,10 @@ func TestClientNext(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
-		_, err := client.Next()
+		_, err := client.Next(mockRepository)
 
 		assert.Error(t, err)
 		assert.Equal(t, "game not yet started", err.Error())
@@ -504,14 +667,10 @@ func TestClientNext(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
-		_, err := client.Next()
+		_, err := client.Next(mockRepository)
 
 		assert.Error(t, err)
 		assert.Equal(t, "game already in progress", err.Error())
@@ -528,14 +687,10 @@ func TestClientNext(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
-		_, err := client.Next()
+		_, err := client.Next(mockRepository)
 
 		assert.Error(t, err)
 		assert.Equal(t, "game completed", err.Error())
@@ -552,14 +707,10 @@ func TestClientNext(t *testing.T) {
 
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
-		_, err := client.Next()
+		_, err := client.Next(mockRepository)
 
 		assert.Error(t, err)
 		assert.Equal(t, "game failed", err.Error())
@@ -632,12 +783,8 @@ func TestClientFailGame(t *testing.T) {
 	t.Run("should fail game", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
 		game := nightfury.Game{Name: "ludo"}
 		client := nightfury.Client{
@@ -656,9 +803,13 @@ func TestClientFailGame(t *testing.T) {
 			},
 		}
 
-		mockRepository.EXPECT().Save("clients", expectedClient)
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(appliedWrites{
+			db.Write{Bucket: "clients", Model: expectedClient},
+			historyWrite("", nightfury.GameplayEvent{Type: nightfury.GameFailed, Game: "ludo", Status: nightfury.Failed}),
+		})
 
-		err := client.FailGame(game)
+		err := client.FailGame(mockRepository, game)
 		assert.NoError(t, err)
 	})
 
@@ -672,7 +823,7 @@ func TestClientFailGame(t *testing.T) {
 			},
 		}
 
-		err := client.FailGame(game)
+		err := client.FailGame(nil, game)
 		assert.Error(t, err)
 		assert.Equal(t, "cannot fail from a Completed game", err.Error())
 	})
@@ -680,12 +831,8 @@ func TestClientFailGame(t *testing.T) {
 	t.Run("should not fail when error on save", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
 		game := nightfury.Game{Name: "ludo"}
 		client := nightfury.Client{
@@ -696,9 +843,10 @@ func TestClientFailGame(t *testing.T) {
 			},
 		}
 
-		mockRepository.EXPECT().Save("clients", gomock.Any()).Return(fmt.Errorf("unable to save"))
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(gomock.Any()).Return(fmt.Errorf("unable to save"))
 
-		err := client.FailGame(game)
+		err := client.FailGame(mockRepository, game)
 		assert.Error(t, err)
 		assert.Equal(t, "unable to save", err.Error())
 	})
@@ -708,12 +856,8 @@ func TestClientCompleteGame(t *testing.T) {
 	t.Run("should complete game", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
 		game := nightfury.Game{Name: "ludo"}
 		client := nightfury.Client{
@@ -732,9 +876,13 @@ func TestClientCompleteGame(t *testing.T) {
 			},
 		}
 
-		mockRepository.EXPECT().Save("clients", expectedClient)
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(appliedWrites{
+			db.Write{Bucket: "clients", Model: expectedClient},
+			historyWrite("", nightfury.GameplayEvent{Type: nightfury.GameCompleted, Game: "ludo", Status: nightfury.Completed}),
+		})
 
-		err := client.CompleteGame(game)
+		err := client.CompleteGame(mockRepository, game)
 		assert.NoError(t, err)
 	})
 
@@ -748,7 +896,7 @@ func TestClientCompleteGame(t *testing.T) {
 			},
 		}
 
-		err := client.CompleteGame(game)
+		err := client.CompleteGame(nil, game)
 		assert.Error(t, err)
 		assert.Equal(t, "cannot complete from a Failed game", err.Error())
 	})
@@ -756,12 +904,8 @@ func TestClientCompleteGame(t *testing.T) {
 	t.Run("should not complete when error on save", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
 		game := nightfury.Game{Name: "ludo"}
 		client := nightfury.Client{
@@ -772,24 +916,156 @@ func TestClientCompleteGame(t *testing.T) {
 			},
 		}
 
-		mockRepository.EXPECT().Save("clients", gomock.Any()).Return(fmt.Errorf("unable to save"))
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(gomock.Any()).Return(fmt.Errorf("unable to save"))
 
-		err := client.CompleteGame(game)
+		err := client.CompleteGame(mockRepository, game)
 		assert.Error(t, err)
 		assert.Equal(t, "unable to save", err.Error())
 	})
 }
 
+func TestClientUpdateProgress(t *testing.T) {
+	t.Run("should save progress of game", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepository := mocks.NewMockRepository(ctrl)
+
+		defer ctrl.Finish()
+
+		progress := nightfury.Progress{Percentage: 50, Score: 10}
+		client := nightfury.Client{
+			GameStatuses: nightfury.GameStatuses{
+				"ludo": {Name: "ludo", Status: nightfury.InProgress},
+			},
+		}
+		expectedClient := nightfury.Client{
+			GameStatuses: nightfury.GameStatuses{
+				"ludo": {Name: "ludo", Status: nightfury.InProgress, Progress: &progress},
+			},
+		}
+
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(appliedWrites{
+			db.Write{Bucket: "clients", Model: expectedClient},
+			historyWrite("", nightfury.GameplayEvent{Type: nightfury.GameProgress, Game: "ludo", Status: nightfury.InProgress, Progress: &progress}),
+		})
+
+		err := client.UpdateProgress(mockRepository, nightfury.Game{Name: "ludo"}, progress)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not save progress of game not in progress", func(t *testing.T) {
+		client := nightfury.Client{
+			GameStatuses: nightfury.GameStatuses{
+				"ludo": {Name: "ludo", Status: nightfury.Completed},
+			},
+		}
+
+		err := client.UpdateProgress(nil, nightfury.Game{Name: "ludo"}, nightfury.Progress{Percentage: 50})
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "cannot record progress of a Completed game", err.Error())
+		}
+	})
+}
+
+func TestClientPause(t *testing.T) {
+	t.Run("should pause the game in progress", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepository := mocks.NewMockRepository(ctrl)
+
+		defer ctrl.Finish()
+
+		client := nightfury.Client{
+			GameStatuses: nightfury.GameStatuses{
+				"tic-tac-toe": {Name: "tic-tac-toe", Status: nightfury.Completed},
+				"ludo":        {Name: "ludo", Status: nightfury.InProgress},
+			},
+		}
+		expectedClient := nightfury.Client{
+			GameStatuses: nightfury.GameStatuses{
+				"tic-tac-toe": {Name: "tic-tac-toe", Status: nightfury.Completed},
+				"ludo":        {Name: "ludo", Status: nightfury.Paused},
+			},
+		}
+
+		mockRepository.EXPECT().Fetch("games", "ludo", gomock.Any()).Return(false, nil)
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(appliedWrites{
+			db.Write{Bucket: "clients", Model: expectedClient},
+			historyWrite("", nightfury.GameplayEvent{Type: nightfury.GamePaused, Game: "ludo", Status: nightfury.Paused}),
+		})
+
+		game, err := client.Pause(mockRepository)
+		assert.NoError(t, err)
+		assert.Equal(t, nightfury.Game{Name: "ludo"}, game)
+	})
+
+	t.Run("should not pause when no game is in progress", func(t *testing.T) {
+		client := nightfury.Client{
+			GameStatuses: nightfury.GameStatuses{
+				"ludo": {Name: "ludo", Status: nightfury.Ready},
+			},
+		}
+
+		_, err := client.Pause(nil)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "no game in progress to pause", err.Error())
+		}
+	})
+}
+
+func TestClientResume(t *testing.T) {
+	t.Run("should resume the paused game", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepository := mocks.NewMockRepository(ctrl)
+
+		defer ctrl.Finish()
+
+		client := nightfury.Client{
+			GameStatuses: nightfury.GameStatuses{
+				"ludo": {Name: "ludo", Status: nightfury.Paused},
+			},
+		}
+		expectedClient := nightfury.Client{
+			GameStatuses: nightfury.GameStatuses{
+				"ludo": {Name: "ludo", Status: nightfury.InProgress},
+			},
+		}
+
+		mockRepository.EXPECT().Fetch("games", "ludo", gomock.Any()).Return(true, nil)
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(appliedWrites{
+			db.Write{Bucket: "clients", Model: expectedClient},
+			historyWrite("", nightfury.GameplayEvent{Type: nightfury.GameResumed, Game: "ludo", Status: nightfury.InProgress}),
+		})
+
+		_, err := client.Resume(mockRepository)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not resume when no game is paused", func(t *testing.T) {
+		client := nightfury.Client{
+			GameStatuses: nightfury.GameStatuses{
+				"ludo": {Name: "ludo", Status: nightfury.InProgress},
+			},
+		}
+
+		_, err := client.Resume(nil)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "no paused game to resume", err.Error())
+		}
+	})
+}
+
 func TestClient_Reset(t *testing.T) {
 	t.Run("should reset", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 		client := nightfury.Client{
 			GameStatuses: nightfury.GameStatuses{
 				"tic-tac-toe":      {Name: "tic-tac-toe", Status: nightfury.Completed},
@@ -806,21 +1082,22 @@ func TestClient_Reset(t *testing.T) {
 			},
 		}
 
-		mockRepository.EXPECT().Save("clients", expectedClient)
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(appliedWrites{
+			db.Write{Bucket: "clients", Model: expectedClient},
+			historyWrite("", nightfury.GameplayEvent{Type: nightfury.GameReset, Game: "ludo", Status: nightfury.Ready}),
+			historyWrite("", nightfury.GameplayEvent{Type: nightfury.GameReset, Game: "tic-tac-toe", Status: nightfury.Ready}),
+		})
 
-		err := client.Reset()
+		err := client.Reset(mockRepository)
 		assert.NoError(t, err)
 	})
 
 	t.Run("should not reset when error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 		client := nightfury.Client{
 			GameStatuses: nightfury.GameStatuses{
 				"tic-tac-toe":      {Name: "tic-tac-toe", Status: nightfury.Completed},
@@ -828,9 +1105,10 @@ func TestClient_Reset(t *testing.T) {
 				"snake-and-ladder": {Name: "snake-and-ladder", Status: nightfury.Ready},
 			},
 		}
-		mockRepository.EXPECT().Save("clients", gomock.Any()).Return(fmt.Errorf("unable to save"))
+		expectStoredClient(mockRepository, client)
+		mockRepository.EXPECT().Apply(gomock.Any()).Return(fmt.Errorf("unable to save"))
 
-		err := client.Reset()
+		err := client.Reset(mockRepository)
 		assert.Error(t, err)
 		assert.Equal(t, "unable to save", err.Error())
 	})
@@ -840,12 +1118,8 @@ func TestClientsDelete(t *testing.T) {
 	t.Run("should be able to save client", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 		client1 := nightfury.Client{Name: "client1", Available: true}
 		client2 := nightfury.Client{Name: "client2", Available: true}
 		clients := nightfury.Clients{"client1": client1, "client2": client2}
@@ -861,12 +1135,8 @@ func TestClientsDelete(t *testing.T) {
 	t.Run("should return error returned by repository save", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepository := mocks.NewMockRepository(ctrl)
-		restore := db.ReplaceDefaultRepositoryWith(mockRepository)
 
-		defer func() {
-			ctrl.Finish()
-			restore()
-		}()
+		defer ctrl.Finish()
 
 		client1 := nightfury.Client{Name: "client1", Available: true}
 		client2 := nightfury.Client{Name: "client2", Available: true}
@@ -880,3 +1150,72 @@ func TestClientsDelete(t *testing.T) {
 		}
 	})
 }
+
+func TestClientProfile(t *testing.T) {
+	connectedAt := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should capture the device the client connected from", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true)
+		device := nightfury.Device{Type: "tablet", IPAddress: "10.0.0.12", UserAgent: "Mozilla/5.0", Version: "1.4.0"}
+
+		actual := client.ConnectedFrom(device, connectedAt)
+
+		assert.Equal(t, nightfury.Profile{
+			DeviceType:     "tablet",
+			IPAddress:      "10.0.0.12",
+			UserAgent:      "Mozilla/5.0",
+			Version:        "1.4.0",
+			ConnectedSince: connectedAt,
+		}, actual.Profile)
+	})
+
+	t.Run("should keep the device type set by admins when the device does not report one", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true)
+		client.Profile = nightfury.Profile{Location: "hall b", DeviceType: "touch-table"}
+
+		actual := client.ConnectedFrom(nightfury.Device{IPAddress: "10.0.0.12"}, connectedAt)
+
+		assert.Equal(t, "touch-table", actual.Profile.DeviceType)
+		assert.Equal(t, "hall b", actual.Profile.Location)
+	})
+
+	t.Run("should edit only the admin editable fields", func(t *testing.T) {
+		profile := nightfury.Profile{IPAddress: "10.0.0.12", Version: "1.4.0", ConnectedSince: connectedAt}
+		edited := nightfury.Profile{Location: "hall b", DeviceType: "tablet", Tags: []string{"vip"}, IPAddress: "127.0.0.1"}
+
+		actual := profile.Edit(edited)
+
+		assert.Equal(t, nightfury.Profile{
+			Location:       "hall b",
+			DeviceType:     "tablet",
+			Tags:           []string{"vip"},
+			IPAddress:      "10.0.0.12",
+			Version:        "1.4.0",
+			ConnectedSince: connectedAt,
+		}, actual)
+	})
+}
+
+func TestClientCurrentGame(t *testing.T) {
+	t.Run("should return the game in progress or paused", func(t *testing.T) {
+		for _, status := range []nightfury.Status{nightfury.InProgress, nightfury.Paused} {
+			client := nightfury.NewClient("kiosk-1", true,
+				nightfury.GameStatus{Name: "seeker", Status: nightfury.Completed},
+				nightfury.GameStatus{Name: "snakes", Status: status},
+			)
+
+			actual, ok := client.CurrentGame()
+
+			assert.True(t, ok)
+			assert.Equal(t, "snakes", actual)
+		}
+	})
+
+	t.Run("should return false when no game is being played", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.Ready})
+
+		_, ok := client.CurrentGame()
+
+		assert.False(t, ok)
+	})
+}
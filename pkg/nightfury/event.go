@@ -0,0 +1,90 @@
+package nightfury
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"time"
+)
+
+var eventsBucketName = "events"
+
+// Event represents a conference or campaign keeping its games, hints, clients and results
+// apart from the other events
+type Event struct {
+	Name      string    `json:"name" binding:"required"`
+	Title     string    `json:"title"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Events represents the collection of Event
+type Events map[string]Event
+
+// NewEventFromRepoWithName return the event from db
+func NewEventFromRepoWithName(repo db.Repository, name string) (Event, error) {
+	event := Event{}
+	ok, err := repo.Fetch(eventsBucketName, Slug(name), &event)
+	if err == nil {
+		if ok {
+			return event, nil
+		}
+		return event, db.EntryNotFound(fmt.Sprintf("event with name %v doesn't exists", name))
+	}
+	return event, err
+}
+
+// NewEventsFromRepo returns all the events from db
+func NewEventsFromRepo(repo db.Repository) (interface{}, error) {
+	return repo.FetchAll(eventsBucketName, func(data []byte) (model db.Model, e error) {
+		event := Event{}
+		err := json.Unmarshal(data, &event)
+		return event, err
+	})
+}
+
+// ActiveEvent returns the active event, false if no event is active
+func ActiveEvent(repo db.Repository) (Event, bool, error) {
+	events, err := NewEventsFromRepo(repo)
+	if err != nil {
+		return Event{}, false, err
+	}
+	models, _ := events.(map[string]interface{})
+	for _, model := range models {
+		if event, ok := model.(Event); ok && event.Active {
+			return event, true, nil
+		}
+	}
+	return Event{}, false, nil
+}
+
+// ID returns the identifiable name for event
+func (e Event) ID() string {
+	return Slug(e.Name)
+}
+
+// Save saves the event information to db
+func (e Event) Save(repo db.Repository) error {
+	return repo.Save(eventsBucketName, e)
+}
+
+// Delete deletes the event information from db
+func (e Event) Delete(repo db.Repository) error {
+	return repo.Delete(eventsBucketName, e)
+}
+
+// Activate marks the event as the active one and the previously active event as inactive
+func (e Event) Activate(repo db.Repository) (Event, error) {
+	active, ok, err := ActiveEvent(repo)
+	if err != nil {
+		return e, err
+	}
+	if ok && active.ID() != e.ID() {
+		active.Active = false
+		if err := active.Save(repo); err != nil {
+			return e, err
+		}
+	}
+	e.Active = true
+	return e, e.Save(repo)
+}
@@ -0,0 +1,83 @@
+package nightfury_test
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	mocks "github.com/boothgames/nightfury/pkg/internal/mocks/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEventID(t *testing.T) {
+	t.Run("should return the id", func(t *testing.T) {
+		event := nightfury.Event{Name: "DevFest 2019"}
+
+		assert.Equal(t, "devfest-2019", event.ID())
+	})
+}
+
+func TestActiveEvent(t *testing.T) {
+	t.Run("should return the active event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().FetchAll("events", gomock.Any()).Return(map[string]interface{}{
+			"devfest":   nightfury.Event{Name: "devfest"},
+			"gophercon": nightfury.Event{Name: "gophercon", Active: true},
+		}, nil)
+
+		actual, ok, err := nightfury.ActiveEvent(repository)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "gophercon", actual.Name)
+	})
+
+	t.Run("should return false when no event is active", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().FetchAll("events", gomock.Any()).Return(map[string]interface{}{}, nil)
+
+		_, ok, err := nightfury.ActiveEvent(repository)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestEventActivate(t *testing.T) {
+	t.Run("should deactivate the previously active event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().FetchAll("events", gomock.Any()).Return(map[string]interface{}{
+			"devfest": nightfury.Event{Name: "devfest", Active: true},
+		}, nil)
+		repository.EXPECT().Save("events", nightfury.Event{Name: "devfest"})
+		repository.EXPECT().Save("events", nightfury.Event{Name: "gophercon", Active: true})
+
+		actual, err := nightfury.Event{Name: "gophercon"}.Activate(repository)
+
+		assert.NoError(t, err)
+		assert.True(t, actual.Active)
+	})
+}
+
+func TestNewEventFromRepoWithName(t *testing.T) {
+	t.Run("should return entry not found when event does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().Fetch("events", "devfest", gomock.Any()).Return(false, nil)
+
+		_, err := nightfury.NewEventFromRepoWithName(repository, "devfest")
+
+		assert.Equal(t, db.EntryNotFound("event with name devfest doesn't exists"), err)
+	})
+}
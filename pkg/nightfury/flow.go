@@ -0,0 +1,224 @@
+package nightfury
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"sort"
+	"strings"
+)
+
+// Available returns the ready games whose prerequisites are completed or skipped and
+// whose branching games are completed, sorted by name
+func (statuses GameStatuses) Available() []string {
+	var available []string
+	for name, status := range statuses {
+		if status.Status == Ready && statuses.prerequisitesMet(name) && statuses.branchReached(name) {
+			available = append(available, name)
+		}
+	}
+	sort.Strings(available)
+	return available
+}
+
+// Choices returns the branches to choose from once their branching game is completed,
+// empty if there is nothing to choose
+func (statuses GameStatuses) Choices() []string {
+	available := map[string]bool{}
+	for _, name := range statuses.Available() {
+		available[name] = true
+	}
+	for _, name := range statuses.names() {
+		status := statuses[name]
+		if status.Status != Completed {
+			continue
+		}
+		var choices []string
+		for _, branch := range status.Branches {
+			if available[branch] {
+				choices = append(choices, branch)
+			}
+		}
+		if len(choices) > 1 {
+			return choices
+		}
+	}
+	return nil
+}
+
+func (statuses GameStatuses) prerequisitesMet(name string) bool {
+	for _, prerequisite := range statuses[name].Prerequisites {
+		if status, ok := statuses[prerequisite]; ok && status.Status != Completed && status.Status != Skipped {
+			return false
+		}
+	}
+	return true
+}
+
+func (statuses GameStatuses) branchReached(name string) bool {
+	for _, parent := range statuses.parents(name) {
+		if statuses[parent].Status != Completed {
+			return false
+		}
+	}
+	return true
+}
+
+// parents returns the games branching into the game
+func (statuses GameStatuses) parents(name string) []string {
+	var parents []string
+	for _, parent := range statuses.names() {
+		if contains(statuses[parent].Branches, name) {
+			parents = append(parents, parent)
+		}
+	}
+	return parents
+}
+
+// skipSiblings skips the other branches of the games branching into the chosen game,
+// along with the branches only reachable through them
+func (statuses GameStatuses) skipSiblings(chosen string) {
+	for _, parent := range statuses.parents(chosen) {
+		for _, sibling := range statuses[parent].Branches {
+			if sibling != chosen {
+				statuses.skip(sibling)
+			}
+		}
+	}
+}
+
+func (statuses GameStatuses) skip(name string) {
+	status, ok := statuses[name]
+	if !ok || status.Status != Ready {
+		return
+	}
+	status.Status = Skipped
+	statuses[name] = status
+	for _, branch := range status.Branches {
+		skippable := true
+		for _, parent := range statuses.parents(branch) {
+			if statuses[parent].Status != Skipped {
+				skippable = false
+			}
+		}
+		if skippable {
+			statuses.skip(branch)
+		}
+	}
+}
+
+func (statuses GameStatuses) names() []string {
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InvalidFlow represents the error of games referring to missing games or forming a cycle
+type InvalidFlow string
+
+// Error returns the error string
+func (i InvalidFlow) Error() string {
+	return string(i)
+}
+
+// ValidateFlow returns error if a game refers to a missing game, or if the prerequisites and branches
+// of the games form a cycle. Games are referred to by their name, as the statuses of the clients are
+func ValidateFlow(games Games) error {
+	next := map[string][]string{}
+	exists := map[string]bool{}
+	for _, game := range games {
+		exists[game.Name] = true
+	}
+	names := make([]string, 0, len(games))
+	for _, game := range games {
+		for _, reference := range append(append([]string{}, game.Prerequisites...), game.Branches...) {
+			if !exists[reference] {
+				return InvalidFlow(fmt.Sprintf("game %v refers to missing game %v", game.Name, reference))
+			}
+		}
+		for _, prerequisite := range game.Prerequisites {
+			next[prerequisite] = append(next[prerequisite], game.Name)
+		}
+		next[game.Name] = append(next[game.Name], game.Branches...)
+		names = append(names, game.Name)
+	}
+	sort.Strings(names)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			for i, step := range path {
+				if step == name {
+					return InvalidFlow(fmt.Sprintf("games form a cycle %v -> %v", strings.Join(path[i:], " -> "), name))
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, following := range next[name] {
+			if err := visit(following); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, name := range names {
+		if state[name] == unvisited {
+			if err := visit(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateGames validates the flow of the games along with the games already in db
+func ValidateGames(repo db.Repository, games ...Game) error {
+	all, err := storedGames(repo)
+	if err != nil {
+		return err
+	}
+	for _, game := range games {
+		all[game.ID()] = game
+	}
+	return ValidateFlow(all)
+}
+
+// ValidateDeletion validates the flow of the games left in db once the game is deleted
+func ValidateDeletion(repo db.Repository, game Game) error {
+	all, err := storedGames(repo)
+	if err != nil {
+		return err
+	}
+	delete(all, game.ID())
+	return ValidateFlow(all)
+}
+
+func storedGames(repo db.Repository) (Games, error) {
+	existing, err := NewGamesFromRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	all := Games{}
+	models, _ := existing.(map[string]interface{})
+	for _, model := range models {
+		if game, ok := model.(Game); ok {
+			all[game.ID()] = game
+		}
+	}
+	return all, nil
+}
@@ -0,0 +1,174 @@
+package nightfury_test
+
+import (
+	"github.com/boothgames/nightfury/pkg/db"
+	mocks "github.com/boothgames/nightfury/pkg/internal/mocks/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func branchingStatuses() nightfury.GameStatuses {
+	return nightfury.GameStatuses{
+		"snakes": {Name: "snakes", Status: nightfury.Ready, Branches: []string{"seeker", "smile"}},
+		"seeker": {Name: "seeker", Status: nightfury.Ready},
+		"smile":  {Name: "smile", Status: nightfury.Ready, Branches: []string{"ludo"}},
+		"ludo":   {Name: "ludo", Status: nightfury.Ready},
+		"final":  {Name: "final", Status: nightfury.Ready, Prerequisites: []string{"seeker", "smile"}},
+	}
+}
+
+func TestGameStatusesAvailable(t *testing.T) {
+	t.Run("should offer games without prerequisites or branching games", func(t *testing.T) {
+		statuses := branchingStatuses()
+
+		assert.Equal(t, []string{"snakes"}, statuses.Available())
+	})
+
+	t.Run("should offer branches once their branching game is completed", func(t *testing.T) {
+		statuses := branchingStatuses()
+		statuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed, Branches: []string{"seeker", "smile"}}
+
+		assert.Equal(t, []string{"seeker", "smile"}, statuses.Available())
+		assert.Equal(t, []string{"seeker", "smile"}, statuses.Choices())
+	})
+
+	t.Run("should offer games once prerequisites are completed or skipped", func(t *testing.T) {
+		statuses := branchingStatuses()
+		statuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed, Branches: []string{"seeker", "smile"}}
+		statuses["seeker"] = nightfury.GameStatus{Name: "seeker", Status: nightfury.Completed}
+		statuses["smile"] = nightfury.GameStatus{Name: "smile", Status: nightfury.Skipped, Branches: []string{"ludo"}}
+		statuses["ludo"] = nightfury.GameStatus{Name: "ludo", Status: nightfury.Skipped}
+
+		assert.Equal(t, []string{"final"}, statuses.Available())
+		assert.Empty(t, statuses.Choices())
+	})
+}
+
+func TestValidateFlow(t *testing.T) {
+	t.Run("should accept games forming a dag", func(t *testing.T) {
+		games := nightfury.Games{
+			"snakes": {Name: "snakes", Branches: []string{"seeker", "smile"}},
+			"seeker": {Name: "seeker"},
+			"smile":  {Name: "smile"},
+			"final":  {Name: "final", Prerequisites: []string{"seeker", "smile"}},
+		}
+
+		assert.NoError(t, nightfury.ValidateFlow(games))
+	})
+
+	t.Run("should reject missing references", func(t *testing.T) {
+		games := nightfury.Games{
+			"snakes": {Name: "snakes", Branches: []string{"seeker"}},
+		}
+
+		err := nightfury.ValidateFlow(games)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "game snakes refers to missing game seeker", err.Error())
+		}
+	})
+
+	t.Run("should reject references to the slug of a game instead of its name", func(t *testing.T) {
+		games := nightfury.Games{
+			"snake-ladder": {Name: "Snake Ladder"},
+			"final":        {Name: "final", Prerequisites: []string{"snake-ladder"}},
+		}
+
+		err := nightfury.ValidateFlow(games)
+
+		assert.Equal(t, nightfury.InvalidFlow("game final refers to missing game snake-ladder"), err)
+	})
+
+	t.Run("should reject cycles", func(t *testing.T) {
+		games := nightfury.Games{
+			"snakes": {Name: "snakes", Branches: []string{"seeker"}},
+			"seeker": {Name: "seeker"},
+			"smile":  {Name: "smile", Prerequisites: []string{"seeker"}, Branches: []string{"snakes"}},
+		}
+
+		err := nightfury.ValidateFlow(games)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "games form a cycle seeker -> smile -> snakes -> seeker", err.Error())
+		}
+	})
+
+	t.Run("should reject games depending on themselves", func(t *testing.T) {
+		games := nightfury.Games{
+			"snakes": {Name: "snakes", Prerequisites: []string{"snakes"}},
+		}
+
+		assert.Error(t, nightfury.ValidateFlow(games))
+	})
+}
+
+func TestClientChoose(t *testing.T) {
+	t.Run("should start the chosen branch and skip the others", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepository := mocks.NewMockRepository(ctrl)
+
+		defer ctrl.Finish()
+
+		statuses := branchingStatuses()
+		statuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed, Branches: []string{"seeker", "smile"}}
+		client := nightfury.Client{Name: "kiosk-1", GameStatuses: statuses}
+		mockRepository.EXPECT().Fetch("games", "smile", gomock.Any()).DoAndReturn(
+			func(bucketName string, name string, model db.Model) (bool, error) {
+				*model.(*nightfury.Game) = nightfury.Game{Name: name}
+				return true, nil
+			})
+		mockRepository.EXPECT().Fetch("clients", "kiosk-1", gomock.Any()).Return(false, nil)
+		mockRepository.EXPECT().Apply(gomock.Any())
+
+		_, err := client.Choose(mockRepository, "smile")
+
+		assert.NoError(t, err)
+		assert.Equal(t, nightfury.InProgress, client.GameStatuses["smile"].Status)
+		assert.Equal(t, nightfury.Skipped, client.GameStatuses["seeker"].Status)
+		assert.Equal(t, nightfury.Ready, client.GameStatuses["ludo"].Status)
+	})
+
+	t.Run("should skip the branches only reachable through skipped branches", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepository := mocks.NewMockRepository(ctrl)
+
+		defer ctrl.Finish()
+
+		statuses := branchingStatuses()
+		statuses["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed, Branches: []string{"seeker", "smile"}}
+		client := nightfury.Client{Name: "kiosk-1", GameStatuses: statuses}
+		mockRepository.EXPECT().Fetch("games", "seeker", gomock.Any()).DoAndReturn(
+			func(bucketName string, name string, model db.Model) (bool, error) {
+				*model.(*nightfury.Game) = nightfury.Game{Name: name}
+				return true, nil
+			})
+		mockRepository.EXPECT().Fetch("clients", "kiosk-1", gomock.Any()).Return(false, nil)
+		mockRepository.EXPECT().Apply(gomock.Any())
+
+		_, err := client.Choose(mockRepository, "seeker")
+
+		assert.NoError(t, err)
+		assert.Equal(t, nightfury.Skipped, client.GameStatuses["ludo"].Status)
+	})
+
+	t.Run("should not choose a game which is not a choice", func(t *testing.T) {
+		client := nightfury.Client{Name: "kiosk-1", GameStatuses: branchingStatuses()}
+
+		_, err := client.Choose(nil, "smile")
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "game smile is not one of the choices", err.Error())
+		}
+	})
+}
+
+func TestClientStatusWithSkippedGames(t *testing.T) {
+	client := nightfury.Client{GameStatuses: nightfury.GameStatuses{
+		"snakes": {Name: "snakes", Status: nightfury.Completed},
+		"seeker": {Name: "seeker", Status: nightfury.Skipped},
+	}}
+
+	assert.Equal(t, nightfury.Completed, client.Status())
+}
@@ -16,6 +16,9 @@ type Game struct {
 	Type        string                 `json:"type" binding:"required"`
 	Mode        string                 `json:"mode"`
 	Metadata    map[string]interface{} `json:"metadata"`
+
+	Prerequisites []string `json:"prerequisites,omitempty"`
+	Branches      []string `json:"branches,omitempty"`
 }
 
 // Games represents collection of games
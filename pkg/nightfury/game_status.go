@@ -10,7 +10,7 @@ type Status int
 
 // String returns the string representation of status
 func (status Status) String() string {
-	return [...]string{"Ready", "InProgress", "Failed", "Completed"}[status]
+	return [...]string{"Ready", "InProgress", "Failed", "Completed", "Paused", "Skipped"}[status]
 }
 
 const (
@@ -25,12 +25,29 @@ const (
 
 	// Completed represents game has been successfully completed
 	Completed
+
+	// Paused represents game in progress which has been paused
+	Paused
+
+	// Skipped represents game of a branch which was not chosen
+	Skipped
 )
 
+// Progress represents the intermediate progress and score reported by a game
+type Progress struct {
+	Percentage float64                `json:"percentage"`
+	Score      int                    `json:"score"`
+	Stats      map[string]interface{} `json:"stats,omitempty"`
+}
+
 // GameStatus represents the game current status
 type GameStatus struct {
-	Name   string `json:"name"`
-	Status Status `json:"status"`
+	Name     string    `json:"name"`
+	Status   Status    `json:"status"`
+	Progress *Progress `json:"progress,omitempty"`
+
+	Prerequisites []string `json:"prerequisites,omitempty"`
+	Branches      []string `json:"branches,omitempty"`
 }
 
 // Failed mark the status as failed
@@ -60,30 +77,76 @@ func (g GameStatus) InProgress() (GameStatus, error) {
 	return g, fmt.Errorf("cannot progress from a %v game", g.Status)
 }
 
+// Paused mark the in progress game as paused
+func (g GameStatus) Paused() (GameStatus, error) {
+	if g.Status == InProgress {
+		g.Status = Paused
+		return g, nil
+	}
+	return g, fmt.Errorf("cannot pause a %v game", g.Status)
+}
+
+// Resumed mark the paused game as in progress
+func (g GameStatus) Resumed() (GameStatus, error) {
+	if g.Status == Paused {
+		g.Status = InProgress
+		return g, nil
+	}
+	return g, fmt.Errorf("cannot resume a %v game", g.Status)
+}
+
+// WithProgress records the progress of an in progress game
+func (g GameStatus) WithProgress(progress Progress) (GameStatus, error) {
+	if g.Status != InProgress {
+		return g, fmt.Errorf("cannot record progress of a %v game", g.Status)
+	}
+	if progress.Percentage < 0 || progress.Percentage > 100 {
+		return g, fmt.Errorf("progress percentage %v is not between 0 and 100", progress.Percentage)
+	}
+	g.Progress = &progress
+	return g, nil
+}
+
 // GameStatuses represents the collection game current status
 type GameStatuses map[string]GameStatus
 
-// ReadyGame returns ready game if any else returns error
-func (statuses GameStatuses) ReadyGame() (Game, error) {
-	repository := db.DefaultRepository()
-	for name, game := range statuses {
-		if game.Status == Ready {
-			return NewGameFromRepoWithName(repository, name)
-		}
+// ReadyGame returns the first available game if any else returns error
+func (statuses GameStatuses) ReadyGame(repo db.Repository) (Game, error) {
+	if available := statuses.Available(); len(available) > 0 {
+		return NewGameFromRepoWithName(repo, available[0])
 	}
 	return Game{}, fmt.Errorf("cannot find any ready game")
 }
 
-// IsAnyGameInProgress returns true if any game is in progress
+// IsAnyGameInProgress returns true if any game is in progress or paused
 func (statuses GameStatuses) IsAnyGameInProgress() bool {
 	for _, game := range statuses {
-		if game.Status == InProgress {
+		if game.Status == InProgress || game.Status == Paused {
 			return true
 		}
 	}
 	return false
 }
 
+// InProgressGame returns the name of the game in progress, false if there is none
+func (statuses GameStatuses) InProgressGame() (string, bool) {
+	return statuses.gameWithStatus(InProgress)
+}
+
+// PausedGame returns the name of the paused game, false if there is none
+func (statuses GameStatuses) PausedGame() (string, bool) {
+	return statuses.gameWithStatus(Paused)
+}
+
+func (statuses GameStatuses) gameWithStatus(status Status) (string, bool) {
+	for name, game := range statuses {
+		if game.Status == status {
+			return name, true
+		}
+	}
+	return "", false
+}
+
 // HasReadyGames returns true if any game is in progress
 func (statuses GameStatuses) HasReadyGames() bool {
 	for _, game := range statuses {
@@ -151,3 +151,125 @@ func TestGameStatusInProgress(t *testing.T) {
 		})
 	}
 }
+
+func TestGameStatusWithProgress(t *testing.T) {
+	progress := Progress{Percentage: 40, Score: 120, Stats: map[string]interface{}{"lives": 2}}
+
+	t.Run("should record progress of an in-progress game", func(t *testing.T) {
+		status := GameStatus{Name: "game", Status: InProgress}
+
+		actual, err := status.WithProgress(progress)
+
+		assert.NoError(t, err)
+		assert.Equal(t, GameStatus{Name: "game", Status: InProgress, Progress: &progress}, actual)
+	})
+
+	t.Run("should keep progress once completed", func(t *testing.T) {
+		status, _ := GameStatus{Name: "game", Status: InProgress}.WithProgress(progress)
+
+		actual, _ := status.Completed()
+
+		assert.Equal(t, GameStatus{Name: "game", Status: Completed, Progress: &progress}, actual)
+	})
+
+	t.Run("should not record progress of a ready game", func(t *testing.T) {
+		status := GameStatus{Name: "game", Status: Ready}
+
+		actual, err := status.WithProgress(progress)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "cannot record progress of a Ready game", err.Error())
+		}
+		assert.Equal(t, status, actual)
+	})
+
+	t.Run("should not record percentage above 100", func(t *testing.T) {
+		status := GameStatus{Name: "game", Status: InProgress}
+
+		_, err := status.WithProgress(Progress{Percentage: 120})
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "progress percentage 120 is not between 0 and 100", err.Error())
+		}
+	})
+}
+
+func TestGameStatusPaused(t *testing.T) {
+	pausedGameStatusScenarios := []gameStatusScenario{
+		{
+			name:           "should be able to pause a in-progress game",
+			status:         InProgress,
+			expectedStatus: Paused,
+		},
+		{
+			name:           "should not pause a ready game",
+			status:         Ready,
+			expectedStatus: Ready,
+			isError:        true,
+			errMsg:         "cannot pause a Ready game",
+		},
+		{
+			name:           "should not pause a paused game",
+			status:         Paused,
+			expectedStatus: Paused,
+			isError:        true,
+			errMsg:         "cannot pause a Paused game",
+		},
+	}
+
+	for _, scenario := range pausedGameStatusScenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			status := GameStatus{Name: "game", Status: scenario.status}
+
+			actual, err := status.Paused()
+
+			if scenario.isError {
+				assert.Error(t, err)
+				assert.Equal(t, scenario.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, GameStatus{Name: "game", Status: scenario.expectedStatus}, actual)
+		})
+	}
+}
+
+func TestGameStatusResumed(t *testing.T) {
+	resumedGameStatusScenarios := []gameStatusScenario{
+		{
+			name:           "should be able to resume a paused game",
+			status:         Paused,
+			expectedStatus: InProgress,
+		},
+		{
+			name:           "should not resume a in-progress game",
+			status:         InProgress,
+			expectedStatus: InProgress,
+			isError:        true,
+			errMsg:         "cannot resume a InProgress game",
+		},
+		{
+			name:           "should not resume a completed game",
+			status:         Completed,
+			expectedStatus: Completed,
+			isError:        true,
+			errMsg:         "cannot resume a Completed game",
+		},
+	}
+
+	for _, scenario := range resumedGameStatusScenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			status := GameStatus{Name: "game", Status: scenario.status}
+
+			actual, err := status.Resumed()
+
+			if scenario.isError {
+				assert.Error(t, err)
+				assert.Equal(t, scenario.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, GameStatus{Name: "game", Status: scenario.expectedStatus}, actual)
+		})
+	}
+}
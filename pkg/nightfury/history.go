@@ -0,0 +1,322 @@
+package nightfury
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"reflect"
+	"sort"
+	"time"
+)
+
+var historyBucketName = "history"
+
+// Types of the gameplay events
+const (
+	RunStarted    = "start"
+	GameAdded     = "added"
+	GameRemoved   = "removed"
+	GameStarted   = "started"
+	GameProgress  = "progress"
+	GameCompleted = "completed"
+	GameFailed    = "failed"
+	GamePaused    = "paused"
+	GameResumed   = "resumed"
+	GameSkipped   = "skipped"
+	GameReset     = "reset"
+)
+
+// GameplayEvent represents a change in the run of a client, recorded in the same transaction as the statuses
+// stored on the client and its team. Replaying the events in order rebuilds the statuses of the client
+type GameplayEvent struct {
+	Sequence string    `json:"id"`
+	Type     string    `json:"type"`
+	Game     string    `json:"game,omitempty"`
+	Status   Status    `json:"status"`
+	Progress *Progress `json:"progress,omitempty"`
+	At       time.Time `json:"at"`
+
+	Prerequisites []string `json:"prerequisites,omitempty"`
+	Branches      []string `json:"branches,omitempty"`
+}
+
+// History represents the gameplay events of a client, oldest first
+type History struct {
+	Client string          `json:"client"`
+	Events []GameplayEvent `json:"events"`
+}
+
+func historyBucket(client string) string {
+	return fmt.Sprintf("%v/%v", historyBucketName, client)
+}
+
+// NewHistoryFromRepoWithName return the history of the client from db, empty if nothing has been recorded
+func NewHistoryFromRepoWithName(repo db.Repository, client string) (History, error) {
+	history := History{Client: client, Events: []GameplayEvent{}}
+	models, err := repo.FetchAll(historyBucket(client), func(data []byte) (model db.Model, e error) {
+		event := GameplayEvent{}
+		err := json.Unmarshal(data, &event)
+		return event, err
+	})
+	if err != nil {
+		return history, err
+	}
+	all, _ := models.(map[string]interface{})
+	for _, model := range all {
+		if event, ok := model.(GameplayEvent); ok {
+			history.Events = append(history.Events, event)
+		}
+	}
+	sort.Slice(history.Events, func(i, j int) bool {
+		return history.Events[i].Sequence < history.Events[j].Sequence
+	})
+	return history, nil
+}
+
+// ID returns the identifiable name for gameplay event
+func (e GameplayEvent) ID() string {
+	return e.Sequence
+}
+
+// Append saves the events at the end of the history in the given order, and returns the history along with them
+func (h History) Append(repo db.Repository, events ...GameplayEvent) (History, error) {
+	for _, event := range events {
+		event.Sequence = sequenceKey(event.At)
+		if err := repo.Save(historyBucket(h.Client), event); err != nil {
+			return h, err
+		}
+		h.Events = append(h.Events, event)
+	}
+	return h, nil
+}
+
+// historyWrites returns the writes appending the events to the history of each client in the given order
+func historyWrites(events []GameplayEvent, clients ...string) []db.Write {
+	var writes []db.Write
+	for i := range events {
+		events[i].Sequence = sequenceKey(events[i].At)
+	}
+	for _, client := range clients {
+		for _, event := range events {
+			writes = append(writes, db.Write{Bucket: historyBucket(client), Model: event})
+		}
+	}
+	return writes
+}
+
+// Delete deletes the events of the history from db
+func (h History) Delete(repo db.Repository) error {
+	for _, event := range h.Events {
+		if err := repo.Delete(historyBucket(h.Client), event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replay returns the status of the games rebuilt from the events
+func (h History) Replay() GameStatuses {
+	statuses := GameStatuses{}
+	for _, event := range h.Events {
+		switch event.Type {
+		case RunStarted:
+		case GameAdded:
+			statuses[event.Game] = GameStatus{
+				Name:          event.Game,
+				Status:        event.Status,
+				Progress:      event.Progress,
+				Prerequisites: event.Prerequisites,
+				Branches:      event.Branches,
+			}
+		case GameRemoved:
+			delete(statuses, event.Game)
+		case GameProgress:
+			status := statuses[event.Game]
+			status.Progress = event.Progress
+			statuses[event.Game] = status
+		default:
+			status := statuses[event.Game]
+			status.Status = event.Status
+			if event.Type == GameReset {
+				status.Progress = nil
+			}
+			statuses[event.Game] = status
+		}
+	}
+	return statuses
+}
+
+// Changes returns the events turning the statuses before into the statuses after, ordered by game name
+func Changes(before, after GameStatuses, at time.Time) []GameplayEvent {
+	names := after.names()
+	for _, name := range before.names() {
+		if _, ok := after[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var events []GameplayEvent
+	for _, name := range names {
+		from, existed := before[name]
+		to, exists := after[name]
+		switch {
+		case !existed:
+			events = append(events, GameplayEvent{Type: GameAdded, Game: name, Status: to.Status,
+				Progress: to.Progress, At: at, Prerequisites: to.Prerequisites, Branches: to.Branches})
+			continue
+		case !exists:
+			events = append(events, GameplayEvent{Type: GameRemoved, Game: name, Status: from.Status, At: at})
+			continue
+		}
+		if to.Progress != nil && !reflect.DeepEqual(from.Progress, to.Progress) {
+			events = append(events, GameplayEvent{Type: GameProgress, Game: name, Status: from.Status, Progress: to.Progress, At: at})
+		}
+		if from.Status != to.Status {
+			events = append(events, GameplayEvent{Type: statusEvent(from.Status, to.Status), Game: name, Status: to.Status, At: at})
+		}
+	}
+	return events
+}
+
+// NewRunStarted returns the event of the client starting its run
+func NewRunStarted(at time.Time) GameplayEvent {
+	return GameplayEvent{Type: RunStarted, At: at}
+}
+
+func statusEvent(from, to Status) string {
+	switch to {
+	case InProgress:
+		if from == Paused {
+			return GameResumed
+		}
+		return GameStarted
+	case Completed:
+		return GameCompleted
+	case Failed:
+		return GameFailed
+	case Paused:
+		return GamePaused
+	case Skipped:
+		return GameSkipped
+	default:
+		return GameReset
+	}
+}
+
+// Copy returns a copy of the statuses, to be compared once the client has played
+func (statuses GameStatuses) Copy() GameStatuses {
+	copied := GameStatuses{}
+	for name, status := range statuses {
+		copied[name] = status
+	}
+	return copied
+}
+
+// gameClock measures the time a game is played, stopped while the game is paused
+type gameClock struct {
+	since   time.Time
+	elapsed time.Duration
+	running bool
+}
+
+func startedClock(at time.Time) *gameClock {
+	return &gameClock{since: at, running: true}
+}
+
+func (c *gameClock) pause(at time.Time) {
+	if c.running {
+		c.elapsed += at.Sub(c.since)
+		c.running = false
+	}
+}
+
+func (c *gameClock) resume(at time.Time) {
+	if !c.running {
+		c.since = at
+		c.running = true
+	}
+}
+
+// until returns the time played up to the given time
+func (c *gameClock) until(at time.Time) time.Duration {
+	if c.running {
+		return c.elapsed + at.Sub(c.since)
+	}
+	return c.elapsed
+}
+
+// GameDuration returns how long the game has been played until the given time since it was last started,
+// excluding the time it was paused, false if the game is not started
+func (h History) GameDuration(game string, at time.Time) (time.Duration, bool) {
+	var clock *gameClock
+	for _, event := range h.Events {
+		if event.Game != game {
+			continue
+		}
+		switch event.Type {
+		case GameStarted:
+			clock = startedClock(event.At)
+		case GamePaused:
+			if clock != nil {
+				clock.pause(event.At)
+			}
+		case GameResumed:
+			if clock != nil {
+				clock.resume(event.At)
+			}
+		case GameCompleted, GameFailed, GameReset, GameRemoved, GameSkipped:
+			clock = nil
+		}
+	}
+	if clock == nil {
+		return 0, false
+	}
+	return clock.until(at), true
+}
+
+// PlayDuration returns how long the game was played until it last completed or failed, excluding
+// the time it was paused, false if it has never completed or failed
+func (h History) PlayDuration(game string) (time.Duration, bool) {
+	for i := len(h.Events) - 1; i >= 0; i-- {
+		event := h.Events[i]
+		if event.Game == game && (event.Type == GameCompleted || event.Type == GameFailed) {
+			return History{Client: h.Client, Events: h.Events[:i]}.GameDuration(game, event.At)
+		}
+	}
+	return 0, false
+}
+
+// runStart returns when the last run recorded in the history started, the first event if no start was recorded
+func (h History) runStart() time.Time {
+	for i := len(h.Events) - 1; i >= 0; i-- {
+		if h.Events[i].Type == RunStarted {
+			return h.Events[i].At
+		}
+	}
+	if len(h.Events) == 0 {
+		return time.Time{}
+	}
+	return h.Events[0].At
+}
+
+// RunDuration returns how long the run started at the given start has been played until the given time,
+// excluding the time its games were paused
+func (h History) RunDuration(started, at time.Time) time.Duration {
+	if started.IsZero() {
+		return 0
+	}
+	clock := startedClock(started)
+	for _, event := range h.Events {
+		if event.At.Before(started) {
+			continue
+		}
+		switch event.Type {
+		case GamePaused:
+			clock.pause(event.At)
+		case GameResumed:
+			clock.resume(event.At)
+		}
+	}
+	return clock.until(at)
+}
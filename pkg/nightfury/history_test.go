@@ -0,0 +1,177 @@
+package nightfury_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/internal/mocks/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestChanges(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should return the events of the games which changed ordered by name", func(t *testing.T) {
+		before := nightfury.GameStatuses{
+			"snakes": {Name: "snakes", Status: nightfury.InProgress},
+			"seeker": {Name: "seeker", Status: nightfury.Ready},
+			"smile":  {Name: "smile", Status: nightfury.Paused},
+			"ludo":   {Name: "ludo", Status: nightfury.Ready},
+		}
+		after := before.Copy()
+		after["snakes"] = nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed, Progress: &nightfury.Progress{Percentage: 100}}
+		after["seeker"] = nightfury.GameStatus{Name: "seeker", Status: nightfury.InProgress}
+		after["smile"] = nightfury.GameStatus{Name: "smile", Status: nightfury.InProgress}
+		delete(after, "ludo")
+		after["maze"] = nightfury.GameStatus{Name: "maze", Status: nightfury.Ready, Prerequisites: []string{"snakes"}}
+
+		actual := nightfury.Changes(before, after, at)
+
+		assert.Equal(t, []string{
+			"ludo " + nightfury.GameRemoved,
+			"maze " + nightfury.GameAdded,
+			"seeker " + nightfury.GameStarted,
+			"smile " + nightfury.GameResumed,
+			"snakes " + nightfury.GameProgress,
+			"snakes " + nightfury.GameCompleted,
+		}, eventNames(actual))
+		assert.Equal(t, []string{"snakes"}, actual[1].Prerequisites)
+		assert.Equal(t, &nightfury.Progress{Percentage: 100}, actual[4].Progress)
+		assert.Equal(t, nightfury.Completed, actual[5].Status)
+		assert.Equal(t, nightfury.InProgress, before["snakes"].Status)
+	})
+
+	t.Run("should return reset for games back to ready", func(t *testing.T) {
+		before := nightfury.GameStatuses{"snakes": {Name: "snakes", Status: nightfury.Failed}}
+		after := nightfury.GameStatuses{"snakes": {Name: "snakes", Status: nightfury.Ready}}
+
+		actual := nightfury.Changes(before, after, at)
+
+		assert.Equal(t, []string{"snakes " + nightfury.GameReset}, eventNames(actual))
+	})
+
+	t.Run("should return nothing when nothing changed", func(t *testing.T) {
+		statuses := nightfury.GameStatuses{"snakes": {Name: "snakes", Status: nightfury.Ready}}
+
+		assert.Empty(t, nightfury.Changes(statuses, statuses.Copy(), at))
+	})
+}
+
+func TestHistoryReplay(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	progress := &nightfury.Progress{Percentage: 40, Score: 12}
+	steps := []nightfury.GameStatuses{
+		{},
+		{"snakes": {Name: "snakes", Status: nightfury.Ready}, "smile": {Name: "smile", Status: nightfury.Ready, Prerequisites: []string{"snakes"}}},
+		{"snakes": {Name: "snakes", Status: nightfury.InProgress}, "smile": {Name: "smile", Status: nightfury.Ready, Prerequisites: []string{"snakes"}}},
+		{"snakes": {Name: "snakes", Status: nightfury.InProgress, Progress: progress}, "smile": {Name: "smile", Status: nightfury.Ready, Prerequisites: []string{"snakes"}}},
+		{"snakes": {Name: "snakes", Status: nightfury.Failed, Progress: progress}, "smile": {Name: "smile", Status: nightfury.Ready, Prerequisites: []string{"snakes"}}},
+		{"snakes": {Name: "snakes", Status: nightfury.Ready}, "smile": {Name: "smile", Status: nightfury.Ready, Prerequisites: []string{"snakes"}}},
+	}
+	history := nightfury.History{Client: "kiosk-1"}
+	for i := 1; i < len(steps); i++ {
+		history.Events = append(history.Events, nightfury.Changes(steps[i-1], steps[i], at.Add(time.Duration(i)*time.Minute))...)
+	}
+
+	t.Run("should derive the statuses at every step", func(t *testing.T) {
+		for i := 1; i < len(steps); i++ {
+			upTo := nightfury.History{Client: "kiosk-1"}
+			for _, event := range history.Events {
+				if !event.At.After(at.Add(time.Duration(i) * time.Minute)) {
+					upTo.Events = append(upTo.Events, event)
+				}
+			}
+
+			assert.Equal(t, steps[i], upTo.Replay(), "step %v", i)
+		}
+	})
+}
+
+func TestHistoryGameDuration(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	history := nightfury.History{Client: "kiosk-1", Events: []nightfury.GameplayEvent{
+		{Type: nightfury.GameStarted, Game: "snakes", At: at},
+		{Type: nightfury.GamePaused, Game: "snakes", At: at.Add(time.Minute)},
+		{Type: nightfury.GameResumed, Game: "snakes", At: at.Add(3 * time.Minute)},
+		{Type: nightfury.GameStarted, Game: "smile", At: at.Add(4 * time.Minute)},
+		{Type: nightfury.GameFailed, Game: "smile", At: at.Add(5 * time.Minute)},
+	}}
+
+	t.Run("should exclude the time the game was paused", func(t *testing.T) {
+		duration, ok := history.GameDuration("snakes", at.Add(5*time.Minute))
+
+		assert.True(t, ok)
+		assert.Equal(t, 3*time.Minute, duration)
+	})
+
+	t.Run("should return false when the game is not started", func(t *testing.T) {
+		_, ok := history.GameDuration("smile", at.Add(6*time.Minute))
+
+		assert.False(t, ok)
+	})
+
+	t.Run("should return how long the game was played until it failed", func(t *testing.T) {
+		duration, ok := history.PlayDuration("smile")
+
+		assert.True(t, ok)
+		assert.Equal(t, time.Minute, duration)
+	})
+
+	t.Run("should return false when the game has not completed or failed", func(t *testing.T) {
+		_, ok := history.PlayDuration("snakes")
+
+		assert.False(t, ok)
+	})
+}
+
+func TestNewHistoryFromRepoWithName(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should return the events oldest first", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		first := nightfury.NewRunStarted(at)
+		first.Sequence = "0000000000000000001-000001"
+		second := nightfury.Changes(nightfury.GameStatuses{}, nightfury.GameStatuses{"snakes": {Name: "snakes"}}, at)[0]
+		second.Sequence = "0000000000000000001-000002"
+		repository.EXPECT().FetchAll("history/kiosk-1", gomock.Any()).DoAndReturn(
+			func(bucketName string, modelFn func(data []byte) (db.Model, error)) (interface{}, error) {
+				models := map[string]interface{}{}
+				for _, event := range []nightfury.GameplayEvent{second, first} {
+					data, _ := json.Marshal(event)
+					model, _ := modelFn(data)
+					models[model.ID()] = model
+				}
+				return models, nil
+			})
+
+		actual, err := nightfury.NewHistoryFromRepoWithName(repository, "kiosk-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{" " + nightfury.RunStarted, "snakes " + nightfury.GameAdded}, eventNames(actual.Events))
+	})
+
+	t.Run("should return error returned by repository", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().FetchAll("history/kiosk-1", gomock.Any()).Return(nil, fmt.Errorf("unable to fetch"))
+
+		_, err := nightfury.NewHistoryFromRepoWithName(repository, "kiosk-1")
+
+		assert.EqualError(t, err, "unable to fetch")
+	})
+}
+
+func eventNames(events []nightfury.GameplayEvent) []string {
+	names := make([]string, 0, len(events))
+	for _, event := range events {
+		names = append(names, event.Game+" "+event.Type)
+	}
+	return names
+}
@@ -0,0 +1,165 @@
+package nightfury
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"io"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+)
+
+var playersBucketName = "players"
+
+var registrationRequired = false
+
+// RequireRegistration sets whether a player has to register on a client before it starts its games
+func RequireRegistration(required bool) {
+	registrationRequired = required
+}
+
+// Unregistered represents the error of starting the games of a client on which no player has registered
+type Unregistered string
+
+func (u Unregistered) Error() string {
+	return string(u)
+}
+
+// Consent represents what the player has agreed to, the privacy policy being required to register
+type Consent struct {
+	Privacy   bool `json:"privacy"`
+	Marketing bool `json:"marketing"`
+}
+
+// Registration represents the details a player fills in before playing
+type Registration struct {
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Consent Consent `json:"consent"`
+}
+
+// Player represents a registered player along with the client they played on
+type Player struct {
+	PlayerID     string    `json:"id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Consent      Consent   `json:"consent"`
+	Client       string    `json:"client"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+// Validate returns error if the name or email is missing or invalid, or if the privacy policy is not agreed to
+func (r Registration) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required to register")
+	}
+	address, err := mail.ParseAddress(r.Email)
+	if err != nil || address.Address != strings.TrimSpace(r.Email) {
+		return fmt.Errorf("email %v is not a valid email address", r.Email)
+	}
+	if !r.Consent.Privacy {
+		return fmt.Errorf("consent to the privacy policy is required to register")
+	}
+	return nil
+}
+
+// NewPlayer returns the player registering on the client, with a random id
+func NewPlayer(registration Registration, client string, at time.Time) (Player, error) {
+	if err := registration.Validate(); err != nil {
+		return Player{}, err
+	}
+	data := make([]byte, 8)
+	if _, err := rand.Read(data); err != nil {
+		return Player{}, fmt.Errorf("unable to generate player id, reason %v", err)
+	}
+	return Player{
+		PlayerID:     hex.EncodeToString(data),
+		Name:         strings.TrimSpace(registration.Name),
+		Email:        strings.TrimSpace(registration.Email),
+		Consent:      registration.Consent,
+		Client:       client,
+		RegisteredAt: at,
+	}, nil
+}
+
+// NewPlayerFromRepoWithID return the player from db
+func NewPlayerFromRepoWithID(repo db.Repository, id string) (Player, error) {
+	player := Player{}
+	ok, err := repo.Fetch(playersBucketName, id, &player)
+	if err == nil {
+		if ok {
+			return player, nil
+		}
+		return player, db.EntryNotFound(fmt.Sprintf("player with id %v doesn't exists", id))
+	}
+	return player, err
+}
+
+// NewPlayersFromRepo returns all the players from db, oldest registration first
+func NewPlayersFromRepo(repo db.Repository) ([]Player, error) {
+	models, err := repo.FetchAll(playersBucketName, func(data []byte) (model db.Model, e error) {
+		player := Player{}
+		err := json.Unmarshal(data, &player)
+		return player, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	players := []Player{}
+	all, _ := models.(map[string]interface{})
+	for _, model := range all {
+		if player, ok := model.(Player); ok {
+			players = append(players, player)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].RegisteredAt.Before(players[j].RegisteredAt)
+	})
+	return players, nil
+}
+
+// ID returns the identifiable name for player
+func (p Player) ID() string {
+	return p.PlayerID
+}
+
+// Redacted returns the player without their name and email
+func (p Player) Redacted() Player {
+	p.Name = ""
+	p.Email = ""
+	return p
+}
+
+// Save saves the player information to db
+func (p Player) Save(repo db.Repository) error {
+	return repo.Save(playersBucketName, p)
+}
+
+// Delete deletes the player information from db
+func (p Player) Delete(repo db.Repository) error {
+	return repo.Delete(playersBucketName, p)
+}
+
+// ExportPlayers writes as csv the name, email and registration time of the players who agreed to marketing,
+// leaving out the other players and the clients they played on
+func ExportPlayers(w io.Writer, players []Player) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"name", "email", "registered_at"}); err != nil {
+		return err
+	}
+	for _, player := range players {
+		if !player.Consent.Marketing {
+			continue
+		}
+		if err := writer.Write([]string{player.Name, player.Email, player.RegisteredAt.UTC().Format(time.RFC3339)}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,86 @@
+package nightfury_test
+
+import (
+	"bytes"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRegistrationValidate(t *testing.T) {
+	consent := nightfury.Consent{Privacy: true}
+
+	assert.NoError(t, nightfury.Registration{Name: "Ada", Email: "ada@example.com", Consent: consent}.Validate())
+	assert.EqualError(t, nightfury.Registration{Name: " ", Email: "ada@example.com", Consent: consent}.Validate(), "name is required to register")
+	assert.EqualError(t, nightfury.Registration{Name: "Ada", Email: "ada", Consent: consent}.Validate(), "email ada is not a valid email address")
+	assert.EqualError(t, nightfury.Registration{Name: "Ada", Email: "Ada <ada@example.com>", Consent: consent}.Validate(),
+		"email Ada <ada@example.com> is not a valid email address")
+	assert.EqualError(t, nightfury.Registration{Name: "Ada", Email: "ada@example.com"}.Validate(), "consent to the privacy policy is required to register")
+}
+
+func TestNewPlayer(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	registration := nightfury.Registration{Name: " Ada ", Email: "ada@example.com", Consent: nightfury.Consent{Privacy: true, Marketing: true}}
+
+	player, err := nightfury.NewPlayer(registration, "kiosk-1", at)
+
+	assert.NoError(t, err)
+	assert.Len(t, player.ID(), 16)
+	assert.Equal(t, "Ada", player.Name)
+	assert.Equal(t, "kiosk-1", player.Client)
+	assert.Equal(t, registration.Consent, player.Consent)
+	assert.Equal(t, at, player.RegisteredAt)
+}
+
+func TestPlayerRedacted(t *testing.T) {
+	player := nightfury.Player{PlayerID: "a1b2", Name: "Ada", Email: "ada@example.com", Client: "kiosk-1"}
+
+	assert.Equal(t, nightfury.Player{PlayerID: "a1b2", Client: "kiosk-1"}, player.Redacted())
+}
+
+func TestExportPlayers(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	players := []nightfury.Player{
+		{PlayerID: "1", Name: "Ada", Email: "ada@example.com", Consent: nightfury.Consent{Privacy: true, Marketing: true}, Client: "kiosk-1", RegisteredAt: at},
+		{PlayerID: "2", Name: "Alan", Email: "alan@example.com", Consent: nightfury.Consent{Privacy: true}, Client: "kiosk-2", RegisteredAt: at},
+	}
+	out := &bytes.Buffer{}
+
+	err := nightfury.ExportPlayers(out, players)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "name,email,registered_at\nAda,ada@example.com,2019-10-01T10:00:00Z\n", out.String())
+}
+
+func TestClientRegistration(t *testing.T) {
+	nightfury.RequireRegistration(true)
+	defer nightfury.RequireRegistration(false)
+
+	t.Run("should refuse to start until a player registers", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes"})
+
+		_, err := client.Start(nil, "snakes")
+
+		assert.IsType(t, nightfury.Unregistered(""), err)
+		assert.EqualError(t, err, "a player has to register on client kiosk-1 before starting")
+	})
+
+	t.Run("should register the player on a ready client", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes"})
+
+		registered, err := client.Register(nightfury.Player{PlayerID: "1"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1", registered.Player)
+		assert.NoError(t, registered.Registered())
+	})
+
+	t.Run("should refuse to register once the games have started", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.InProgress})
+
+		_, err := client.Register(nightfury.Player{PlayerID: "1"})
+
+		assert.EqualError(t, err, "cannot register on client kiosk-1 once its games have started")
+	})
+}
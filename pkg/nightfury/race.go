@@ -0,0 +1,192 @@
+package nightfury
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"sort"
+	"time"
+)
+
+var racesBucketName = "races"
+
+// Race represents the same list of games played head to head by clients
+type Race struct {
+	Name      string                       `json:"name" binding:"required"`
+	Clients   []string                     `json:"clients" binding:"required,min=2"`
+	Games     []string                     `json:"games" binding:"required,min=1"`
+	Status    Status                       `json:"status"`
+	StartedAt time.Time                    `json:"startedAt"`
+	Assigned  map[string][]string          `json:"assigned,omitempty"`
+	Results   map[string]map[string]Result `json:"results"`
+	Winner    string                       `json:"winner,omitempty"`
+	Standings []Standing                   `json:"standings"`
+}
+
+// Result represents a game of the race completed or failed by a client, keyed by the client and the game
+type Result struct {
+	Client string    `json:"client"`
+	Game   string    `json:"game"`
+	Failed bool      `json:"failed,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// Standing represents the position of a client in the race
+type Standing struct {
+	Position  int           `json:"position"`
+	Client    string        `json:"client"`
+	Completed int           `json:"completed"`
+	Finished  bool          `json:"finished"`
+	Failed    bool          `json:"failed"`
+	Elapsed   time.Duration `json:"elapsed"`
+}
+
+// Races represents the collection of Race
+type Races map[string]Race
+
+// NewRace return a new instance of race yet to be started
+func NewRace(name string, clients []string, games []string) Race {
+	return Race{
+		Name:    name,
+		Clients: clients,
+		Games:   games,
+		Status:  Ready,
+	}
+}
+
+// NewRaceFromRepoWithName return the race from db
+func NewRaceFromRepoWithName(repo db.Repository, name string) (Race, error) {
+	race := Race{}
+	ok, err := repo.Fetch(racesBucketName, name, &race)
+	if err == nil {
+		if ok {
+			return race, nil
+		}
+		return race, db.EntryNotFound(fmt.Sprintf("race with name %v doesn't exists", name))
+	}
+	return race, err
+}
+
+// NewRacesFromRepo returns all the races from db
+func NewRacesFromRepo(repo db.Repository) (interface{}, error) {
+	return repo.FetchAll(racesBucketName, func(data []byte) (model db.Model, e error) {
+		race := Race{}
+		err := json.Unmarshal(data, &race)
+		return race, err
+	})
+}
+
+// ID returns the identifiable name for race
+func (r Race) ID() string {
+	return r.Name
+}
+
+// Save saves the race information to db
+func (r Race) Save(repo db.Repository) error {
+	return repo.Save(racesBucketName, r)
+}
+
+// Delete deletes the race information from db
+func (r Race) Delete(repo db.Repository) error {
+	return repo.Delete(racesBucketName, r)
+}
+
+// Start marks the race as in progress from the given time, returns error if the race has already started
+func (r Race) Start(at time.Time) (Race, error) {
+	if r.Status != Ready {
+		return r, fmt.Errorf("race %v has already started", r.Name)
+	}
+	r.Status = InProgress
+	r.StartedAt = at
+	r.Standings = r.standings()
+	return r, nil
+}
+
+// Record records the game completed or failed by the client and updates the standings, returns error if
+// the game of the client already has a result. The first client to complete every game wins, and the race
+// completes once every client has completed or failed
+func (r Race) Record(client, game string, failed bool, at time.Time) (Race, error) {
+	if r.Status != InProgress {
+		return r, fmt.Errorf("race %v is not in progress", r.Name)
+	}
+	if !contains(r.Clients, client) {
+		return r, fmt.Errorf("client %v is not racing in %v", client, r.Name)
+	}
+	if !contains(r.Games, game) {
+		return r, fmt.Errorf("game %v is not part of race %v", game, r.Name)
+	}
+	if _, ok := r.Results[client][game]; ok {
+		return r, fmt.Errorf("game %v of client %v already has a result in race %v", game, client, r.Name)
+	}
+	r.Results = r.withResult(Result{Client: client, Game: game, Failed: failed, At: at})
+	r.Standings = r.standings()
+
+	done := 0
+	for _, standing := range r.Standings {
+		if standing.Finished && r.Winner == "" {
+			r.Winner = standing.Client
+		}
+		if standing.Finished || standing.Failed {
+			done++
+		}
+	}
+	if done == len(r.Clients) {
+		r.Status = Completed
+	}
+	return r, nil
+}
+
+// withResult returns a copy of the results along with the result, leaving the results of the race as they are
+func (r Race) withResult(result Result) map[string]map[string]Result {
+	results := map[string]map[string]Result{}
+	for client, games := range r.Results {
+		results[client] = map[string]Result{}
+		for game, recorded := range games {
+			results[client][game] = recorded
+		}
+	}
+	if results[result.Client] == nil {
+		results[result.Client] = map[string]Result{}
+	}
+	results[result.Client][result.Game] = result
+	return results
+}
+
+// standings orders the clients by the games they completed, ties are broken by who completed them first
+func (r Race) standings() []Standing {
+	standings := make([]Standing, 0, len(r.Clients))
+	for _, client := range r.Clients {
+		standing := Standing{Client: client}
+		for _, result := range r.Results[client] {
+			if result.Failed {
+				standing.Failed = true
+				continue
+			}
+			standing.Completed++
+			if elapsed := result.At.Sub(r.StartedAt); elapsed > standing.Elapsed {
+				standing.Elapsed = elapsed
+			}
+		}
+		standing.Finished = standing.Completed == len(r.Games)
+		standings = append(standings, standing)
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Completed != standings[j].Completed {
+			return standings[i].Completed > standings[j].Completed
+		}
+		return standings[i].Elapsed < standings[j].Elapsed
+	})
+	for i := range standings {
+		standings[i].Position = i + 1
+	}
+	return standings
+}
+
+func contains(items []string, item string) bool {
+	for _, value := range items {
+		if value == item {
+			return true
+		}
+	}
+	return false
+}
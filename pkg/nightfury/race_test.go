@@ -0,0 +1,100 @@
+package nightfury_test
+
+import (
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRaceStart(t *testing.T) {
+	startedAt := time.Date(2019, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should start race", func(t *testing.T) {
+		race := nightfury.NewRace("final", []string{"kiosk-1", "kiosk-2"}, []string{"ludo"})
+
+		actual, err := race.Start(startedAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, nightfury.InProgress, actual.Status)
+		assert.Equal(t, startedAt, actual.StartedAt)
+		assert.Len(t, actual.Standings, 2)
+	})
+
+	t.Run("should not start race twice", func(t *testing.T) {
+		race, _ := nightfury.NewRace("final", []string{"kiosk-1", "kiosk-2"}, []string{"ludo"}).Start(startedAt)
+
+		_, err := race.Start(startedAt)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "race final has already started", err.Error())
+		}
+	})
+}
+
+func TestRaceRecord(t *testing.T) {
+	startedAt := time.Date(2019, 8, 1, 10, 0, 0, 0, time.UTC)
+	race, _ := nightfury.NewRace("final", []string{"kiosk-1", "kiosk-2", "kiosk-3"}, []string{"ludo", "snakes"}).Start(startedAt)
+
+	t.Run("should order standings by games completed and time taken", func(t *testing.T) {
+		actual, _ := race.Record("kiosk-2", "ludo", false, startedAt.Add(time.Minute))
+		actual, _ = actual.Record("kiosk-1", "ludo", false, startedAt.Add(2*time.Minute))
+		actual, _ = actual.Record("kiosk-1", "snakes", false, startedAt.Add(3*time.Minute))
+
+		expected := []nightfury.Standing{
+			{Position: 1, Client: "kiosk-1", Completed: 2, Finished: true, Elapsed: 3 * time.Minute},
+			{Position: 2, Client: "kiosk-2", Completed: 1, Elapsed: time.Minute},
+			{Position: 3, Client: "kiosk-3"},
+		}
+		assert.Equal(t, expected, actual.Standings)
+		assert.Equal(t, "kiosk-1", actual.Winner)
+		assert.Equal(t, nightfury.InProgress, actual.Status)
+	})
+
+	t.Run("should complete race once every client has finished or failed", func(t *testing.T) {
+		actual, _ := race.Record("kiosk-1", "ludo", false, startedAt.Add(time.Minute))
+		actual, _ = actual.Record("kiosk-1", "snakes", false, startedAt.Add(2*time.Minute))
+		actual, _ = actual.Record("kiosk-2", "ludo", true, startedAt.Add(3*time.Minute))
+		actual, _ = actual.Record("kiosk-3", "snakes", true, startedAt.Add(4*time.Minute))
+
+		assert.Equal(t, nightfury.Completed, actual.Status)
+		assert.True(t, actual.Standings[2].Failed)
+	})
+
+	t.Run("should keep the first result of a game of a client", func(t *testing.T) {
+		actual, _ := race.Record("kiosk-1", "ludo", false, startedAt.Add(time.Minute))
+
+		actual, err := actual.Record("kiosk-1", "ludo", false, startedAt.Add(2*time.Minute))
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "game ludo of client kiosk-1 already has a result in race final", err.Error())
+		}
+		assert.Equal(t, 1, actual.Standings[0].Completed)
+		assert.Equal(t, time.Minute, actual.Standings[0].Elapsed)
+		assert.Empty(t, race.Results)
+	})
+
+	t.Run("should reject client not racing", func(t *testing.T) {
+		_, err := race.Record("kiosk-4", "ludo", false, startedAt)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "client kiosk-4 is not racing in final", err.Error())
+		}
+	})
+
+	t.Run("should reject game not part of race", func(t *testing.T) {
+		_, err := race.Record("kiosk-1", "chess", false, startedAt)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "game chess is not part of race final", err.Error())
+		}
+	})
+
+	t.Run("should reject race not started", func(t *testing.T) {
+		_, err := nightfury.NewRace("final", []string{"kiosk-1"}, []string{"ludo"}).Record("kiosk-1", "ludo", false, startedAt)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "race final is not in progress", err.Error())
+		}
+	})
+}
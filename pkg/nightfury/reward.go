@@ -0,0 +1,277 @@
+package nightfury
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var prizesBucketName = "prizes"
+var rewardsBucketName = "rewards"
+
+// rewardsLock serializes the changes to the inventory so that a prize is never allocated beyond its quantity
+var rewardsLock = new(sync.Mutex)
+
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+const codeLength = 8
+
+// Prize represents a prize of the inventory, allocated to the runs meeting its rules while its quantity lasts
+type Prize struct {
+	Name     string      `json:"name" binding:"required"`
+	Title    string      `json:"title"`
+	Quantity int         `json:"quantity"`
+	Rank     int         `json:"rank"`
+	Rules    Eligibility `json:"rules"`
+}
+
+// Eligibility represents the rules a completed run has to meet to win a prize, a run meets empty rules
+type Eligibility struct {
+	Within   string `json:"within,omitempty"`
+	MinScore int    `json:"minScore,omitempty"`
+}
+
+// Run represents a completed run of a client
+type Run struct {
+	Duration time.Duration
+	Score    int
+}
+
+// Reward represents a prize allocated to a client, redeemed at the booth with its code
+type Reward struct {
+	Code        string     `json:"code"`
+	Prize       string     `json:"prize"`
+	Title       string     `json:"title,omitempty"`
+	Client      string     `json:"client"`
+	Player      string     `json:"player,omitempty"`
+	AllocatedAt time.Time  `json:"allocatedAt"`
+	RedeemedAt  *time.Time `json:"redeemedAt,omitempty"`
+	RedeemedBy  string     `json:"redeemedBy,omitempty"`
+}
+
+// RewardRedeemed represents the error of redeeming a reward twice
+type RewardRedeemed string
+
+func (r RewardRedeemed) Error() string {
+	return string(r)
+}
+
+// NewPrizeFromRepoWithName return the prize from db
+func NewPrizeFromRepoWithName(repo db.Repository, name string) (Prize, error) {
+	prize := Prize{}
+	ok, err := repo.Fetch(prizesBucketName, Slug(name), &prize)
+	if err == nil {
+		if ok {
+			return prize, nil
+		}
+		return prize, db.EntryNotFound(fmt.Sprintf("prize with name %v doesn't exists", name))
+	}
+	return prize, err
+}
+
+// NewPrizesFromRepo returns all the prizes from db
+func NewPrizesFromRepo(repo db.Repository) (interface{}, error) {
+	return repo.FetchAll(prizesBucketName, func(data []byte) (model db.Model, e error) {
+		prize := Prize{}
+		err := json.Unmarshal(data, &prize)
+		return prize, err
+	})
+}
+
+// ID returns the identifiable name for prize
+func (p Prize) ID() string {
+	return Slug(p.Name)
+}
+
+// Save saves the prize information to db
+func (p Prize) Save(repo db.Repository) error {
+	rewardsLock.Lock()
+	defer rewardsLock.Unlock()
+	return repo.Save(prizesBucketName, p)
+}
+
+// Delete deletes the prize information from db, the rewards already allocated are kept
+func (p Prize) Delete(repo db.Repository) error {
+	rewardsLock.Lock()
+	defer rewardsLock.Unlock()
+	return repo.Delete(prizesBucketName, p)
+}
+
+// Validate returns error if the quantity is negative or the rules cannot be parsed
+func (p Prize) Validate() error {
+	if p.Quantity < 0 {
+		return fmt.Errorf("quantity of prize %v cannot be negative", p.Name)
+	}
+	if p.Rules.Within != "" {
+		within, err := time.ParseDuration(p.Rules.Within)
+		if err != nil || within <= 0 {
+			return fmt.Errorf("within of prize %v should be a positive duration, e.g. 5m", p.Name)
+		}
+	}
+	return nil
+}
+
+// Eligible returns true if the run meets the rules of the prize
+func (p Prize) Eligible(run Run) bool {
+	if p.Rules.Within != "" {
+		within, err := time.ParseDuration(p.Rules.Within)
+		if err != nil || run.Duration > within {
+			return false
+		}
+	}
+	return run.Score >= p.Rules.MinScore
+}
+
+// NewRun returns the run completed by the client at the given time, timed from the start of the run stored
+// on the client, and shared by its team, without the time its games were paused. The run of a client stored
+// without its start is timed from the history
+func NewRun(client Client, history History, at time.Time) Run {
+	started := client.StartedAt
+	if started.IsZero() {
+		started = history.runStart()
+	}
+	run := Run{Duration: history.RunDuration(started, at)}
+	for _, status := range client.GameStatuses {
+		if status.Progress != nil {
+			run.Score += status.Progress.Score
+		}
+	}
+	return run
+}
+
+// AllocateReward allocates the first prize by rank, then name, left in the inventory whose rules the run meets,
+// false if there is none. The reward is saved along with the prize left in the same transaction
+func AllocateReward(repo db.Repository, client Client, run Run, at time.Time) (Reward, bool, error) {
+	rewardsLock.Lock()
+	defer rewardsLock.Unlock()
+
+	rewarded, err := alreadyRewarded(repo, client)
+	if err != nil || rewarded {
+		return Reward{}, false, err
+	}
+	models, err := NewPrizesFromRepo(repo)
+	if err != nil {
+		return Reward{}, false, err
+	}
+	var prizes []Prize
+	all, _ := models.(map[string]interface{})
+	for _, model := range all {
+		if prize, ok := model.(Prize); ok && prize.Quantity > 0 && prize.Eligible(run) {
+			prizes = append(prizes, prize)
+		}
+	}
+	if len(prizes) == 0 {
+		return Reward{}, false, nil
+	}
+	sort.Slice(prizes, func(i, j int) bool {
+		if prizes[i].Rank != prizes[j].Rank {
+			return prizes[i].Rank < prizes[j].Rank
+		}
+		return prizes[i].Name < prizes[j].Name
+	})
+	prize := prizes[0]
+
+	code, err := newRewardCode(repo)
+	if err != nil {
+		return Reward{}, false, err
+	}
+	reward := Reward{Code: code, Prize: prize.Name, Title: prize.Title, Client: client.Name, Player: client.Player, AllocatedAt: at}
+	prize.Quantity--
+	err = repo.Apply(db.Write{Bucket: rewardsBucketName, Model: reward}, db.Write{Bucket: prizesBucketName, Model: prize})
+	if err != nil {
+		return Reward{}, false, err
+	}
+	return reward, true, nil
+}
+
+// alreadyRewarded returns true if the player of the client already won a reward, or the client holds a reward
+// which has not been redeemed yet, so that a kiosk completing its games over and over does not drain the inventory
+func alreadyRewarded(repo db.Repository, client Client) (bool, error) {
+	models, err := NewRewardsFromRepo(repo)
+	if err != nil {
+		return false, err
+	}
+	all, _ := models.(map[string]interface{})
+	for _, model := range all {
+		reward, ok := model.(Reward)
+		if !ok {
+			continue
+		}
+		if client.Player != "" && reward.Player == client.Player {
+			return true, nil
+		}
+		if reward.Client == client.Name && reward.RedeemedAt == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RedeemReward marks the reward with the code, in any case, as handed out by the staff member
+func RedeemReward(repo db.Repository, code, by string, at time.Time) (Reward, error) {
+	rewardsLock.Lock()
+	defer rewardsLock.Unlock()
+
+	reward, err := NewRewardFromRepoWithCode(repo, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return reward, err
+	}
+	if reward.RedeemedAt != nil {
+		return reward, RewardRedeemed(fmt.Sprintf("reward %v has already been redeemed at %v", reward.Code, reward.RedeemedAt.Format(time.RFC3339)))
+	}
+	reward.RedeemedAt = &at
+	reward.RedeemedBy = by
+	return reward, repo.Save(rewardsBucketName, reward)
+}
+
+// NewRewardFromRepoWithCode return the reward from db
+func NewRewardFromRepoWithCode(repo db.Repository, code string) (Reward, error) {
+	reward := Reward{}
+	ok, err := repo.Fetch(rewardsBucketName, code, &reward)
+	if err == nil {
+		if ok {
+			return reward, nil
+		}
+		return reward, db.EntryNotFound(fmt.Sprintf("reward with code %v doesn't exists", code))
+	}
+	return reward, err
+}
+
+// NewRewardsFromRepo returns all the rewards from db
+func NewRewardsFromRepo(repo db.Repository) (interface{}, error) {
+	return repo.FetchAll(rewardsBucketName, func(data []byte) (model db.Model, e error) {
+		reward := Reward{}
+		err := json.Unmarshal(data, &reward)
+		return reward, err
+	})
+}
+
+// ID returns the identifiable name for reward
+func (r Reward) ID() string {
+	return r.Code
+}
+
+// newRewardCode returns a random code not used by any reward, leaving out the characters easily mistaken for others
+func newRewardCode(repo db.Repository) (string, error) {
+	for {
+		data := make([]byte, codeLength)
+		if _, err := rand.Read(data); err != nil {
+			return "", fmt.Errorf("unable to generate reward code, reason %v", err)
+		}
+		for i := range data {
+			data[i] = codeAlphabet[int(data[i])%len(codeAlphabet)]
+		}
+		code := string(data)
+		ok, err := repo.Fetch(rewardsBucketName, code, &Reward{})
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return code, nil
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package nightfury_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/internal/mocks/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPrizeValidate(t *testing.T) {
+	assert.NoError(t, nightfury.Prize{Name: "t-shirt", Quantity: 10, Rules: nightfury.Eligibility{Within: "5m"}}.Validate())
+	assert.EqualError(t, nightfury.Prize{Name: "t-shirt", Quantity: -1}.Validate(), "quantity of prize t-shirt cannot be negative")
+	assert.EqualError(t, nightfury.Prize{Name: "t-shirt", Rules: nightfury.Eligibility{Within: "soon"}}.Validate(),
+		"within of prize t-shirt should be a positive duration, e.g. 5m")
+}
+
+func TestPrizeEligible(t *testing.T) {
+	fast := nightfury.Prize{Name: "hoodie", Rules: nightfury.Eligibility{Within: "5m"}}
+	scored := nightfury.Prize{Name: "mug", Rules: nightfury.Eligibility{MinScore: 100}}
+
+	assert.True(t, nightfury.Prize{Name: "sticker"}.Eligible(nightfury.Run{Duration: time.Hour}))
+	assert.True(t, fast.Eligible(nightfury.Run{Duration: 4 * time.Minute}))
+	assert.False(t, fast.Eligible(nightfury.Run{Duration: 6 * time.Minute}))
+	assert.True(t, scored.Eligible(nightfury.Run{Score: 120}))
+	assert.False(t, scored.Eligible(nightfury.Run{Score: 80}))
+}
+
+func TestNewRun(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	client := nightfury.NewClient("kiosk-1", true,
+		nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed, Progress: &nightfury.Progress{Score: 40}},
+		nightfury.GameStatus{Name: "seeker", Status: nightfury.Completed, Progress: &nightfury.Progress{Score: 60}},
+		nightfury.GameStatus{Name: "smile", Status: nightfury.Completed},
+	)
+	history := nightfury.History{Client: "kiosk-1", Events: []nightfury.GameplayEvent{
+		{Type: nightfury.RunStarted, At: at},
+		{Type: nightfury.GameReset, Game: "snakes", At: at.Add(time.Minute)},
+		{Type: nightfury.RunStarted, At: at.Add(2 * time.Minute)},
+		{Type: nightfury.GamePaused, Game: "seeker", At: at.Add(3 * time.Minute)},
+		{Type: nightfury.GameResumed, Game: "seeker", At: at.Add(5 * time.Minute)},
+	}}
+
+	t.Run("should time the run from the start stored on the client", func(t *testing.T) {
+		client.StartedAt = at.Add(time.Minute)
+
+		actual := nightfury.NewRun(client, history, at.Add(6*time.Minute))
+
+		assert.Equal(t, nightfury.Run{Duration: 3 * time.Minute, Score: 100}, actual)
+	})
+
+	t.Run("should time the run from the last start in history when the client has no start", func(t *testing.T) {
+		client.StartedAt = time.Time{}
+
+		actual := nightfury.NewRun(client, history, at.Add(6*time.Minute))
+
+		assert.Equal(t, nightfury.Run{Duration: 2 * time.Minute, Score: 100}, actual)
+	})
+}
+
+func TestAllocateReward(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	client := nightfury.NewClient("kiosk-1", true)
+	prizes := []nightfury.Prize{
+		{Name: "sticker", Quantity: 100, Rank: 2},
+		{Name: "hoodie", Quantity: 1, Rank: 1, Rules: nightfury.Eligibility{Within: "5m"}},
+		{Name: "mug", Quantity: 0, Rank: 0},
+	}
+	rewards := []nightfury.Reward{
+		{Code: "K7QX4P9M", Prize: "sticker", Client: "kiosk-2", Player: "ada-example-com", AllocatedAt: at},
+		{Code: "R3DM2W8Q", Prize: "sticker", Client: "kiosk-3", AllocatedAt: at},
+	}
+	fetchRewards := func(repository *mocks.MockRepository) {
+		repository.EXPECT().FetchAll("rewards", gomock.Any()).DoAndReturn(
+			func(bucketName string, modelFn func(data []byte) (db.Model, error)) (interface{}, error) {
+				models := map[string]interface{}{}
+				for _, reward := range rewards {
+					data, _ := json.Marshal(reward)
+					model, _ := modelFn(data)
+					models[model.ID()] = model
+				}
+				return models, nil
+			})
+	}
+	fetch := func(repository *mocks.MockRepository) {
+		fetchRewards(repository)
+		repository.EXPECT().FetchAll("prizes", gomock.Any()).DoAndReturn(
+			func(bucketName string, modelFn func(data []byte) (db.Model, error)) (interface{}, error) {
+				models := map[string]interface{}{}
+				for _, prize := range prizes {
+					data, _ := json.Marshal(prize)
+					model, _ := modelFn(data)
+					models[model.ID()] = model
+				}
+				return models, nil
+			})
+	}
+
+	t.Run("should allocate the first prize by rank left for the run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		fetch(repository)
+		repository.EXPECT().Fetch("rewards", gomock.Any(), gomock.Any()).Return(false, nil)
+		repository.EXPECT().Apply(gomock.Any(), db.Write{Bucket: "prizes", Model: nightfury.Prize{Name: "hoodie", Quantity: 0, Rank: 1, Rules: nightfury.Eligibility{Within: "5m"}}}).Return(nil)
+
+		reward, ok, err := nightfury.AllocateReward(repository, client, nightfury.Run{Duration: 3 * time.Minute}, at)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "hoodie", reward.Prize)
+		assert.Equal(t, "kiosk-1", reward.Client)
+		assert.Regexp(t, "^[A-HJ-NP-Z2-9]{8}$", reward.Code)
+		assert.Equal(t, at, reward.AllocatedAt)
+	})
+
+	t.Run("should skip the prizes whose rules the run does not meet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		fetch(repository)
+		repository.EXPECT().Fetch("rewards", gomock.Any(), gomock.Any()).Return(false, nil)
+		repository.EXPECT().Apply(gomock.Any(), db.Write{Bucket: "prizes", Model: nightfury.Prize{Name: "sticker", Quantity: 99, Rank: 2}}).Return(nil)
+
+		reward, ok, _ := nightfury.AllocateReward(repository, client, nightfury.Run{Duration: 10 * time.Minute}, at)
+
+		assert.True(t, ok)
+		assert.Equal(t, "sticker", reward.Prize)
+	})
+
+	t.Run("should allocate nothing when the reward cannot be saved along with the prize", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		fetch(repository)
+		repository.EXPECT().Fetch("rewards", gomock.Any(), gomock.Any()).Return(false, nil)
+		repository.EXPECT().Apply(gomock.Any(), gomock.Any()).Return(fmt.Errorf("disk full"))
+
+		_, ok, err := nightfury.AllocateReward(repository, client, nightfury.Run{}, at)
+
+		assert.EqualError(t, err, "disk full")
+		assert.False(t, ok)
+	})
+
+	t.Run("should allocate nothing when no prize is left", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		fetchRewards(repository)
+		repository.EXPECT().FetchAll("prizes", gomock.Any()).Return(map[string]interface{}{}, nil)
+
+		_, ok, err := nightfury.AllocateReward(repository, client, nightfury.Run{}, at)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("should allocate nothing to a player who already won a reward", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		fetchRewards(repository)
+		registered := nightfury.NewClient("kiosk-1", true)
+		registered.Player = "ada-example-com"
+
+		_, ok, err := nightfury.AllocateReward(repository, registered, nightfury.Run{}, at)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("should allocate nothing to a client holding a reward not redeemed yet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		fetchRewards(repository)
+
+		_, ok, err := nightfury.AllocateReward(repository, nightfury.NewClient("kiosk-3", true), nightfury.Run{}, at)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestRedeemReward(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	reward := nightfury.Reward{Code: "K7QX4P9M", Prize: "hoodie", Client: "kiosk-1", AllocatedAt: at}
+
+	t.Run("should mark the reward redeemed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().Fetch("rewards", "K7QX4P9M", gomock.Any()).SetArg(2, reward).Return(true, nil)
+		redeemedAt := at.Add(time.Hour)
+		expected := reward
+		expected.RedeemedAt = &redeemedAt
+		expected.RedeemedBy = "alice"
+		repository.EXPECT().Save("rewards", expected).Return(nil)
+
+		actual, err := nightfury.RedeemReward(repository, " k7qx4p9m", "alice", redeemedAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("should refuse to redeem a reward twice", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		redeemed := reward
+		redeemedAt := at.Add(time.Hour)
+		redeemed.RedeemedAt = &redeemedAt
+		repository.EXPECT().Fetch("rewards", "K7QX4P9M", gomock.Any()).SetArg(2, redeemed).Return(true, nil)
+
+		_, err := nightfury.RedeemReward(repository, "K7QX4P9M", "alice", at.Add(2*time.Hour))
+
+		assert.IsType(t, nightfury.RewardRedeemed(""), err)
+		assert.EqualError(t, err, "reward K7QX4P9M has already been redeemed at 2019-10-01T11:00:00Z")
+	})
+
+	t.Run("should fail for an unknown code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().Fetch("rewards", "UNKNOWN", gomock.Any()).Return(false, nil)
+
+		_, err := nightfury.RedeemReward(repository, "UNKNOWN", "alice", at)
+
+		assert.IsType(t, db.EntryNotFound(""), err)
+	})
+}
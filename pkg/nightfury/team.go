@@ -0,0 +1,150 @@
+package nightfury
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"time"
+)
+
+var teamsBucketName = "teams"
+
+// Team represents the clients sharing one run of games
+type Team struct {
+	Name         string       `json:"name" binding:"required"`
+	Members      []string     `json:"members" binding:"required,min=1"`
+	GameStatuses GameStatuses `json:"gameStatuses"`
+	StartedAt    time.Time    `json:"startedAt"`
+}
+
+// Teams represents the collection of Team
+type Teams map[string]Team
+
+// NewTeam return a new instance of team with empty list of games
+func NewTeam(name string, members ...string) Team {
+	return Team{
+		Name:         name,
+		Members:      members,
+		GameStatuses: GameStatuses{},
+	}
+}
+
+// NewTeamFromRepoWithName return the team from db
+func NewTeamFromRepoWithName(repo db.Repository, name string) (Team, error) {
+	team := Team{}
+	ok, err := repo.Fetch(teamsBucketName, name, &team)
+	if err == nil {
+		if ok {
+			return team, nil
+		}
+		return team, db.EntryNotFound(fmt.Sprintf("team with name %v doesn't exists", name))
+	}
+	return team, err
+}
+
+// NewTeamsFromRepo returns all the teams from db
+func NewTeamsFromRepo(repo db.Repository) (interface{}, error) {
+	return repo.FetchAll(teamsBucketName, func(data []byte) (model db.Model, e error) {
+		team := Team{}
+		err := json.Unmarshal(data, &team)
+		return team, err
+	})
+}
+
+// ID returns the identifiable name for team
+func (t Team) ID() string {
+	return t.Name
+}
+
+// HasMember checks if the client is a member of the team
+func (t Team) HasMember(name string) bool {
+	return contains(t.Members, name)
+}
+
+// Save saves the team information to db
+func (t Team) Save(repo db.Repository) error {
+	return repo.Save(teamsBucketName, t)
+}
+
+// Delete deletes the team information from db
+func (t Team) Delete(repo db.Repository) error {
+	return repo.Delete(teamsBucketName, t)
+}
+
+// Form saves the team and attaches its members in a single transaction, the games of every member
+// become one progression shared by the team and recorded in the history of every member
+func (t Team) Form(repo db.Repository) error {
+	if t.GameStatuses == nil {
+		t.GameStatuses = GameStatuses{}
+	}
+	members := make([]Client, 0, len(t.Members))
+	for _, name := range t.Members {
+		client, err := NewClientFromRepoWithName(repo, name)
+		if err != nil {
+			return err
+		}
+		if client.Team != "" && client.Team != t.Name {
+			return fmt.Errorf("client %v is already a member of team %v", name, client.Team)
+		}
+		for gameName, gameStatus := range client.GameStatuses {
+			t.GameStatuses[gameName] = GameStatus{
+				Name:          gameName,
+				Status:        Ready,
+				Prerequisites: gameStatus.Prerequisites,
+				Branches:      gameStatus.Branches,
+			}
+		}
+		members = append(members, client)
+	}
+	now := time.Now()
+	writes := []db.Write{{Bucket: teamsBucketName, Model: t}}
+	for _, client := range members {
+		events := Changes(client.GameStatuses, t.GameStatuses, now)
+		client.Team = t.Name
+		client.GameStatuses = t.GameStatuses
+		client.StartedAt = t.StartedAt
+		writes = append(writes, db.Write{Bucket: clientsBucketName, Model: client})
+		writes = append(writes, historyWrites(events, client.Name)...)
+	}
+	return repo.Apply(writes...)
+}
+
+// Disband deletes the team and detaches its members in a single transaction, the members keep a copy
+// of the team progression
+func (t Team) Disband(repo db.Repository) error {
+	var writes []db.Write
+	for _, name := range t.Members {
+		client, err := NewClientFromRepoWithName(repo, name)
+		if _, ok := err.(db.EntryNotFound); ok {
+			continue
+		} else if err != nil {
+			return err
+		}
+		client.Team = ""
+		writes = append(writes, db.Write{Bucket: clientsBucketName, Model: client})
+	}
+	return repo.Apply(append(writes, db.Write{Bucket: teamsBucketName, Model: t, Delete: true})...)
+}
+
+// share returns the writes saving the game statuses of the member and the start of their run to the team
+// and the other members
+func (t Team) share(repo db.Repository, member Client) ([]db.Write, error) {
+	t.GameStatuses = member.GameStatuses
+	t.StartedAt = member.StartedAt
+	writes := []db.Write{{Bucket: teamsBucketName, Model: t}}
+	for _, name := range t.Members {
+		if name == member.Name {
+			continue
+		}
+		client, err := NewClientFromRepoWithName(repo, name)
+		if _, ok := err.(db.EntryNotFound); ok {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		client.GameStatuses = member.GameStatuses
+		client.StartedAt = member.StartedAt
+		writes = append(writes, db.Write{Bucket: clientsBucketName, Model: client})
+	}
+	return writes, nil
+}
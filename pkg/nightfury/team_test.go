@@ -0,0 +1,138 @@
+package nightfury_test
+
+import (
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	mocks "github.com/boothgames/nightfury/pkg/internal/mocks/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTeamHasMember(t *testing.T) {
+	team := nightfury.NewTeam("red", "kiosk-1", "kiosk-2")
+
+	t.Run("should have member", func(t *testing.T) {
+		assert.True(t, team.HasMember("kiosk-2"))
+	})
+
+	t.Run("should not have member", func(t *testing.T) {
+		assert.False(t, team.HasMember("kiosk-3"))
+	})
+}
+
+func TestNewTeamFromRepoWithName(t *testing.T) {
+	t.Run("should fetch the team from db", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().Fetch("teams", "red", gomock.Any()).Return(true, nil)
+
+		_, err := nightfury.NewTeamFromRepoWithName(repository, "red")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return entry not found when team does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().Fetch("teams", "red", gomock.Any()).Return(false, nil)
+
+		_, err := nightfury.NewTeamFromRepoWithName(repository, "red")
+
+		assert.Equal(t, db.EntryNotFound("team with name red doesn't exists"), err)
+	})
+}
+
+func TestTeamForm(t *testing.T) {
+	t.Run("should share games of members and record them in their history", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		members := map[string]nightfury.Client{
+			"kiosk-1": nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "ludo", Status: nightfury.Completed}),
+			"kiosk-2": nightfury.NewClient("kiosk-2", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.Ready}),
+		}
+		expected := nightfury.GameStatuses{
+			"ludo":   {Name: "ludo", Status: nightfury.Ready},
+			"snakes": {Name: "snakes", Status: nightfury.Ready},
+		}
+		repository.EXPECT().Fetch("clients", gomock.Any(), gomock.Any()).Times(2).DoAndReturn(
+			func(bucketName string, name string, model db.Model) (bool, error) {
+				*model.(*nightfury.Client) = members[name]
+				return true, nil
+			})
+		repository.EXPECT().Apply(appliedWrites{
+			db.Write{Bucket: "teams", Model: nightfury.Team{Name: "red", Members: []string{"kiosk-1", "kiosk-2"}, GameStatuses: expected}},
+			db.Write{Bucket: "clients", Model: nightfury.Client{Name: "kiosk-1", Available: true, Team: "red", GameStatuses: expected}},
+			historyWrite("kiosk-1", nightfury.GameplayEvent{Type: nightfury.GameReset, Game: "ludo", Status: nightfury.Ready}),
+			historyWrite("kiosk-1", nightfury.GameplayEvent{Type: nightfury.GameAdded, Game: "snakes", Status: nightfury.Ready}),
+			db.Write{Bucket: "clients", Model: nightfury.Client{Name: "kiosk-2", Available: true, Team: "red", GameStatuses: expected}},
+			historyWrite("kiosk-2", nightfury.GameplayEvent{Type: nightfury.GameAdded, Game: "ludo", Status: nightfury.Ready}),
+		})
+
+		err := nightfury.NewTeam("red", "kiosk-1", "kiosk-2").Form(repository)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("should fail when member belongs to another team", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().Fetch("clients", "kiosk-1", gomock.Any()).DoAndReturn(
+			func(bucketName string, name string, model db.Model) (bool, error) {
+				*model.(*nightfury.Client) = nightfury.Client{Name: "kiosk-1", Team: "blue"}
+				return true, nil
+			})
+
+		err := nightfury.NewTeam("red", "kiosk-1").Form(repository)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "client kiosk-1 is already a member of team blue", err.Error())
+		}
+	})
+
+	t.Run("should return error returned while fetching member", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().Fetch("clients", "kiosk-1", gomock.Any()).Return(false, fmt.Errorf("unable to fetch"))
+
+		err := nightfury.NewTeam("red", "kiosk-1").Form(repository)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "unable to fetch", err.Error())
+		}
+	})
+}
+
+func TestTeamDisband(t *testing.T) {
+	t.Run("should detach members and delete team in a single transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repository := mocks.NewMockRepository(ctrl)
+		team := nightfury.NewTeam("red", "kiosk-1")
+		repository.EXPECT().Fetch("clients", "kiosk-1", gomock.Any()).DoAndReturn(
+			func(bucketName string, name string, model db.Model) (bool, error) {
+				*model.(*nightfury.Client) = nightfury.Client{Name: "kiosk-1", Team: "red"}
+				return true, nil
+			})
+		repository.EXPECT().Apply(
+			db.Write{Bucket: "clients", Model: nightfury.Client{Name: "kiosk-1"}},
+			db.Write{Bucket: "teams", Model: team, Delete: true},
+		)
+
+		err := team.Disband(repository)
+
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,204 @@
+package nightfury
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/pkg/db"
+	"net/url"
+	"sort"
+	"time"
+)
+
+var webhooksBucketName = "webhooks"
+var deliveriesBucketName = "deliveries"
+
+// Events notified to the webhooks
+const (
+	ClientCompletedNotification = "client.completed"
+	ClientFailedNotification    = "client.failed"
+	ClientResetNotification     = "client.reset"
+)
+
+var notificationEvents = []string{ClientCompletedNotification, ClientFailedNotification, ClientResetNotification}
+
+// Webhook represents an url notified of the events it subscribes to, every event if it subscribes to none.
+// The bodies are signed with the secret
+type Webhook struct {
+	Name   string   `json:"name" binding:"required"`
+	URL    string   `json:"url" binding:"required"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// Webhooks represents the collection of Webhook
+type Webhooks map[string]Webhook
+
+// NewWebhookFromRepoWithName return the webhook from db
+func NewWebhookFromRepoWithName(repo db.Repository, name string) (Webhook, error) {
+	webhook := Webhook{}
+	ok, err := repo.Fetch(webhooksBucketName, Slug(name), &webhook)
+	if err == nil {
+		if ok {
+			return webhook, nil
+		}
+		return webhook, db.EntryNotFound(fmt.Sprintf("webhook with name %v doesn't exists", name))
+	}
+	return webhook, err
+}
+
+// NewWebhooksFromRepo returns all the webhooks from db
+func NewWebhooksFromRepo(repo db.Repository) (interface{}, error) {
+	return repo.FetchAll(webhooksBucketName, func(data []byte) (model db.Model, e error) {
+		webhook := Webhook{}
+		err := json.Unmarshal(data, &webhook)
+		return webhook, err
+	})
+}
+
+// ID returns the identifiable name for webhook
+func (w Webhook) ID() string {
+	return Slug(w.Name)
+}
+
+// Save saves the webhook information to db
+func (w Webhook) Save(repo db.Repository) error {
+	return repo.Save(webhooksBucketName, w)
+}
+
+// Delete deletes the webhook information along with its deliveries from db
+func (w Webhook) Delete(repo db.Repository) error {
+	deliveries, err := NewDeliveriesFromRepo(repo, w.Name)
+	if err != nil {
+		return err
+	}
+	for _, delivery := range deliveries {
+		if err := repo.Delete(deliveriesBucket(w.Name), delivery); err != nil {
+			return err
+		}
+	}
+	return repo.Delete(webhooksBucketName, w)
+}
+
+// Validate returns error if the url is not an absolute http url, the webhook has no secret to sign with
+// or an event cannot be notified
+func (w Webhook) Validate() error {
+	target, err := url.Parse(w.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("url of webhook %v should be an absolute http or https url", w.Name)
+	}
+	if w.Secret == "" {
+		return fmt.Errorf("secret of webhook %v cannot be empty", w.Name)
+	}
+	for _, event := range w.Events {
+		if !contains(notificationEvents, event) {
+			return fmt.Errorf("webhook %v cannot subscribe to %v, should be one of %v", w.Name, event, notificationEvents)
+		}
+	}
+	return nil
+}
+
+// Subscribes returns true if the webhook is notified of the event
+func (w Webhook) Subscribes(event string) bool {
+	return len(w.Events) == 0 || contains(w.Events, event)
+}
+
+// Redacted returns the webhook without its secret
+func (w Webhook) Redacted() Webhook {
+	w.Secret = ""
+	return w
+}
+
+// Notification represents the body posted to the webhooks
+type Notification struct {
+	ID     string       `json:"id"`
+	Event  string       `json:"event"`
+	At     time.Time    `json:"at"`
+	Client string       `json:"client"`
+	Status Status       `json:"status"`
+	Games  GameStatuses `json:"games"`
+	Reward *Reward      `json:"reward,omitempty"`
+}
+
+// NewNotification returns the notification of the event which happened to the client
+func NewNotification(event string, client Client, at time.Time) Notification {
+	return Notification{ID: sequenceKey(at), Event: event, At: at, Client: client.Name, Status: client.Status(), Games: client.GameStatuses}
+}
+
+// Notifications returns the events to notify when the games of the client change from before
+func Notifications(before GameStatuses, client Client, events []GameplayEvent) []string {
+	var notifications []string
+	previous := Client{GameStatuses: before}.Status()
+	current := client.Status()
+	if current == Completed && previous != Completed {
+		notifications = append(notifications, ClientCompletedNotification)
+	}
+	if current == Failed && previous != Failed {
+		notifications = append(notifications, ClientFailedNotification)
+	}
+	for _, event := range events {
+		if event.Type == GameReset {
+			notifications = append(notifications, ClientResetNotification)
+			break
+		}
+	}
+	return notifications
+}
+
+// DeliveryAttempt represents one request posting a notification to a webhook
+type DeliveryAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Delivery represents the attempts to post a notification to a webhook, kept as the delivery log
+type Delivery struct {
+	Sequence  string            `json:"id"`
+	Webhook   string            `json:"webhook"`
+	Event     string            `json:"event"`
+	Client    string            `json:"client"`
+	Delivered bool              `json:"delivered"`
+	Attempts  []DeliveryAttempt `json:"attempts"`
+}
+
+// NewDelivery returns the delivery of the notification to the webhook, yet to be attempted
+func NewDelivery(webhook Webhook, notification Notification) Delivery {
+	return Delivery{Sequence: notification.ID, Webhook: webhook.Name, Event: notification.Event, Client: notification.Client, Attempts: []DeliveryAttempt{}}
+}
+
+// NewDeliveriesFromRepo returns the deliveries to the webhook from db, oldest first
+func NewDeliveriesFromRepo(repo db.Repository, webhook string) ([]Delivery, error) {
+	models, err := repo.FetchAll(deliveriesBucket(webhook), func(data []byte) (model db.Model, e error) {
+		delivery := Delivery{}
+		err := json.Unmarshal(data, &delivery)
+		return delivery, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []Delivery{}
+	all, _ := models.(map[string]interface{})
+	for _, model := range all {
+		if delivery, ok := model.(Delivery); ok {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].Sequence < deliveries[j].Sequence
+	})
+	return deliveries, nil
+}
+
+func deliveriesBucket(webhook string) string {
+	return fmt.Sprintf("%v/%v", deliveriesBucketName, Slug(webhook))
+}
+
+// ID returns the identifiable name for delivery
+func (d Delivery) ID() string {
+	return d.Sequence
+}
+
+// Save saves the delivery information to db
+func (d Delivery) Save(repo db.Repository) error {
+	return repo.Save(deliveriesBucket(d.Webhook), d)
+}
@@ -0,0 +1,74 @@
+package nightfury_test
+
+import (
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWebhookValidate(t *testing.T) {
+	t.Run("should accept an http url and known events", func(t *testing.T) {
+		webhook := nightfury.Webhook{Name: "prize-desk", URL: "https://prizes.example.com/hooks", Secret: "s3cret", Events: []string{nightfury.ClientCompletedNotification}}
+
+		assert.NoError(t, webhook.Validate())
+	})
+
+	t.Run("should refuse a relative url", func(t *testing.T) {
+		webhook := nightfury.Webhook{Name: "prize-desk", URL: "/hooks"}
+
+		assert.EqualError(t, webhook.Validate(), "url of webhook prize-desk should be an absolute http or https url")
+	})
+
+	t.Run("should refuse a webhook without secret", func(t *testing.T) {
+		webhook := nightfury.Webhook{Name: "prize-desk", URL: "https://prizes.example.com/hooks"}
+
+		assert.EqualError(t, webhook.Validate(), "secret of webhook prize-desk cannot be empty")
+	})
+
+	t.Run("should refuse an unknown event", func(t *testing.T) {
+		webhook := nightfury.Webhook{Name: "prize-desk", URL: "http://localhost:8080", Secret: "s3cret", Events: []string{"client.started"}}
+
+		assert.EqualError(t, webhook.Validate(), "webhook prize-desk cannot subscribe to client.started, should be one of [client.completed client.failed client.reset]")
+	})
+}
+
+func TestWebhookSubscribes(t *testing.T) {
+	all := nightfury.Webhook{Name: "chat-bot"}
+	completions := nightfury.Webhook{Name: "prize-desk", Events: []string{nightfury.ClientCompletedNotification}}
+
+	assert.True(t, all.Subscribes(nightfury.ClientResetNotification))
+	assert.True(t, completions.Subscribes(nightfury.ClientCompletedNotification))
+	assert.False(t, completions.Subscribes(nightfury.ClientFailedNotification))
+}
+
+func TestNotifications(t *testing.T) {
+	inProgress := nightfury.GameStatuses{
+		"snakes": {Name: "snakes", Status: nightfury.Completed},
+		"seeker": {Name: "seeker", Status: nightfury.InProgress},
+	}
+
+	t.Run("should notify the completion of the client", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed}, nightfury.GameStatus{Name: "seeker", Status: nightfury.Completed})
+
+		assert.Equal(t, []string{nightfury.ClientCompletedNotification}, nightfury.Notifications(inProgress, client, nil))
+	})
+
+	t.Run("should notify the failure of the client", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed}, nightfury.GameStatus{Name: "seeker", Status: nightfury.Failed})
+
+		assert.Equal(t, []string{nightfury.ClientFailedNotification}, nightfury.Notifications(inProgress, client, nil))
+	})
+
+	t.Run("should notify the reset of the client", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes"}, nightfury.GameStatus{Name: "seeker"})
+		events := []nightfury.GameplayEvent{{Type: nightfury.GameReset, Game: "seeker"}, {Type: nightfury.GameReset, Game: "snakes"}}
+
+		assert.Equal(t, []string{nightfury.ClientResetNotification}, nightfury.Notifications(inProgress, client, events))
+	})
+
+	t.Run("should not notify progress in the run", func(t *testing.T) {
+		client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed}, nightfury.GameStatus{Name: "seeker", Status: nightfury.Paused})
+
+		assert.Empty(t, nightfury.Notifications(inProgress, client, nil))
+	})
+}
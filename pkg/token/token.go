@@ -0,0 +1,118 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var defaultSigner *Signer
+
+// Claims represents the identity bound to a join token
+type Claims struct {
+	ClientID  string `json:"client"`
+	GameName  string `json:"game,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Token represents a signed join token
+type Token struct {
+	Value     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Signer issues and verifies HMAC signed join tokens
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewSigner returns a signer which issues tokens valid for ttl
+func NewSigner(secret []byte, ttl time.Duration) Signer {
+	return Signer{secret: secret, ttl: ttl, now: time.Now}
+}
+
+// Issue returns a token binding the client id and game name.
+// An empty game name issues a token for the client socket
+func (s Signer) Issue(clientID, gameName string) (Token, error) {
+	expiresAt := s.now().Add(s.ttl)
+	claims := Claims{ClientID: clientID, GameName: gameName, ExpiresAt: expiresAt.Unix()}
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return Token{}, err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	value := fmt.Sprintf("%s.%s", payload, s.sign(payload))
+	return Token{Value: value, ExpiresAt: time.Unix(claims.ExpiresAt, 0)}, nil
+}
+
+// Verify returns error if the token is not signed by the signer, has expired
+// or is not bound to the given client id and game name
+func (s Signer) Verify(value, clientID, gameName string) error {
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 {
+		return fmt.Errorf("malformed token")
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(s.sign(parts[0]))) {
+		return fmt.Errorf("invalid token signature")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("malformed token")
+	}
+	claims := Claims{}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return fmt.Errorf("malformed token")
+	}
+	if s.now().Unix() >= claims.ExpiresAt {
+		return fmt.Errorf("token expired")
+	}
+	if claims.ClientID != clientID || claims.GameName != gameName {
+		return fmt.Errorf("token not issued for client '%v' and game '%v'", clientID, gameName)
+	}
+	return nil
+}
+
+func (s Signer) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	_, _ = mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Initialize enables join tokens with the global signer.
+// A random secret is generated when secret is empty
+func Initialize(secret string, ttl time.Duration) error {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return fmt.Errorf("unable to generate token secret, reason %v", err)
+		}
+	}
+	signer := NewSigner(key, ttl)
+	defaultSigner = &signer
+	return nil
+}
+
+// DefaultSigner returns the global signer, false if join tokens are disabled
+func DefaultSigner() (Signer, bool) {
+	if defaultSigner == nil {
+		return Signer{}, false
+	}
+	return *defaultSigner, true
+}
+
+// ReplaceDefaultSignerWith replace the default signer
+func ReplaceDefaultSignerWith(signer *Signer) func() {
+	originalSigner := defaultSigner
+	defaultSigner = signer
+	return func() {
+		defaultSigner = originalSigner
+	}
+}
@@ -0,0 +1,91 @@
+package token_test
+
+import (
+	"github.com/boothgames/nightfury/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSignerVerify(t *testing.T) {
+	signer := token.NewSigner([]byte("secret"), time.Minute)
+
+	t.Run("should verify token issued for the client and game", func(t *testing.T) {
+		issued, err := signer.Issue("kiosk-1", "snakes")
+		assert.NoError(t, err)
+
+		err = signer.Verify(issued.Value, "kiosk-1", "snakes")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject token issued for a different game", func(t *testing.T) {
+		issued, _ := signer.Issue("kiosk-1", "snakes")
+
+		err := signer.Verify(issued.Value, "kiosk-1", "smile")
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "token not issued for client 'kiosk-1' and game 'smile'", err.Error())
+		}
+	})
+
+	t.Run("should reject client token used for a game", func(t *testing.T) {
+		issued, _ := signer.Issue("kiosk-1", "")
+
+		err := signer.Verify(issued.Value, "kiosk-1", "snakes")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject token signed with a different secret", func(t *testing.T) {
+		issued, _ := token.NewSigner([]byte("other"), time.Minute).Issue("kiosk-1", "snakes")
+
+		err := signer.Verify(issued.Value, "kiosk-1", "snakes")
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "invalid token signature", err.Error())
+		}
+	})
+
+	t.Run("should reject expired token", func(t *testing.T) {
+		issued, _ := token.NewSigner([]byte("secret"), -time.Second).Issue("kiosk-1", "snakes")
+
+		err := signer.Verify(issued.Value, "kiosk-1", "snakes")
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "token expired", err.Error())
+		}
+	})
+
+	t.Run("should reject malformed token", func(t *testing.T) {
+		err := signer.Verify("not-a-token", "kiosk-1", "snakes")
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "malformed token", err.Error())
+		}
+	})
+}
+
+func TestDefaultSigner(t *testing.T) {
+	t.Run("should be disabled until initialized", func(t *testing.T) {
+		restore := token.ReplaceDefaultSignerWith(nil)
+		defer restore()
+
+		_, ok := token.DefaultSigner()
+
+		assert.False(t, ok)
+	})
+
+	t.Run("should generate a secret when none is given", func(t *testing.T) {
+		restore := token.ReplaceDefaultSignerWith(nil)
+		defer restore()
+
+		err := token.Initialize("", time.Minute)
+		assert.NoError(t, err)
+
+		signer, ok := token.DefaultSigner()
+		assert.True(t, ok)
+		issued, _ := signer.Issue("kiosk-1", "")
+		assert.NoError(t, signer.Verify(issued.Value, "kiosk-1", ""))
+	})
+}
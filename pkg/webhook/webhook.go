@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/boothgames/nightfury/log"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Headers of the requests posted to the webhooks
+const (
+	SignatureHeader = "X-Nightfury-Signature"
+	EventHeader     = "X-Nightfury-Event"
+	DeliveryHeader  = "X-Nightfury-Delivery"
+)
+
+var defaultDispatcher = NewDispatcher(10*time.Second, 5, time.Second)
+
+// Dispatcher posts the notifications to the webhooks subscribed to them, retrying failed
+// requests with an exponential backoff until it is drained
+type Dispatcher struct {
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	pending     *sync.WaitGroup
+	draining    chan struct{}
+	drainOnce   *sync.Once
+	ctx         context.Context
+	abort       context.CancelFunc
+}
+
+// NewDispatcher returns a dispatcher making up to maxAttempts requests of timeout for each notification,
+// waiting backoff after the first failure and twice as long after every other
+func NewDispatcher(timeout time.Duration, maxAttempts int, backoff time.Duration) Dispatcher {
+	ctx, abort := context.WithCancel(context.Background())
+	return Dispatcher{
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		pending:     new(sync.WaitGroup),
+		draining:    make(chan struct{}),
+		drainOnce:   new(sync.Once),
+		ctx:         ctx,
+		abort:       abort,
+	}
+}
+
+// DefaultDispatcher returns the global dispatcher
+func DefaultDispatcher() Dispatcher {
+	return defaultDispatcher
+}
+
+// ReplaceDefaultDispatcherWith replace the default dispatcher
+func ReplaceDefaultDispatcherWith(dispatcher Dispatcher) func() {
+	originalDispatcher := defaultDispatcher
+	defaultDispatcher = dispatcher
+	return func() {
+		defaultDispatcher = originalDispatcher
+	}
+}
+
+// Dispatch delivers the notification in the background to every webhook subscribed to its event
+func (d Dispatcher) Dispatch(repo db.Repository, notification nightfury.Notification) {
+	models, err := nightfury.NewWebhooksFromRepo(repo)
+	if err != nil {
+		log.Errorf("cannot load webhooks to notify %v. Error: %v", notification.Event, err)
+		return
+	}
+	// encoded before going in the background, as the notification refers to the state of the caller
+	body, err := json.Marshal(notification)
+	if err != nil {
+		log.Errorf("cannot encode %v notification. Error: %v", notification.Event, err)
+		return
+	}
+	all, _ := models.(map[string]interface{})
+	for _, model := range all {
+		if webhook, ok := model.(nightfury.Webhook); ok && webhook.Subscribes(notification.Event) {
+			d.pending.Add(1)
+			go func(webhook nightfury.Webhook) {
+				defer d.pending.Done()
+				d.deliver(repo, webhook, notification, body)
+			}(webhook)
+		}
+	}
+}
+
+// Wait blocks until the deliveries in the background are over
+func (d Dispatcher) Wait() {
+	d.pending.Wait()
+}
+
+// Drain stops retrying failed deliveries and blocks until the deliveries in the background are over.
+// The requests still going on after timeout are aborted and their notifications dropped
+func (d Dispatcher) Drain(timeout time.Duration) error {
+	d.drainOnce.Do(func() { close(d.draining) })
+	done := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		d.abort()
+		return fmt.Errorf("webhook deliveries still pending after %v were dropped", timeout)
+	}
+}
+
+// Deliver posts the notification to the webhook until it is accepted or the attempts run out,
+// recording every attempt in the delivery log
+func (d Dispatcher) Deliver(repo db.Repository, webhook nightfury.Webhook, notification nightfury.Notification) nightfury.Delivery {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		log.WithFields(log.Fields{log.ClientField: notification.Client}).Errorf("cannot encode %v notification. Error: %v", notification.Event, err)
+		return nightfury.NewDelivery(webhook, notification)
+	}
+	return d.deliver(repo, webhook, notification, body)
+}
+
+func (d Dispatcher) deliver(repo db.Repository, webhook nightfury.Webhook, notification nightfury.Notification, body []byte) nightfury.Delivery {
+	delivery := nightfury.NewDelivery(webhook, notification)
+	logger := log.WithFields(log.Fields{log.ClientField: notification.Client})
+	wait := d.backoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		result, retry := d.post(webhook, notification, body)
+		delivery.Attempts = append(delivery.Attempts, result)
+		delivery.Delivered = result.Error == ""
+		if err := delivery.Save(repo); err != nil {
+			logger.Errorf("cannot log delivery %v to webhook %v. Error: %v", delivery.ID(), webhook.Name, err)
+		}
+		if delivery.Delivered || !retry {
+			break
+		}
+		logger.Warnf("delivery %v to webhook %v failed, attempt %v of %v. Error: %v", delivery.ID(), webhook.Name, attempt, d.maxAttempts, result.Error)
+		if attempt < d.maxAttempts && !d.wait(wait) {
+			logger.Warnf("not retrying delivery %v to webhook %v while shutting down", delivery.ID(), webhook.Name)
+			break
+		}
+		wait *= 2
+	}
+	if !delivery.Delivered {
+		logger.Errorf("gave up delivering %v to webhook %v", notification.Event, webhook.Name)
+	}
+	return delivery
+}
+
+// wait sleeps for the backoff, returns false if the dispatcher is drained in the meantime
+func (d Dispatcher) wait(backoff time.Duration) bool {
+	select {
+	case <-d.draining:
+		return false
+	case <-time.After(backoff):
+		return true
+	}
+}
+
+// post returns the attempt, and whether it is worth retrying when it failed
+func (d Dispatcher) post(webhook nightfury.Webhook, notification nightfury.Notification, body []byte) (nightfury.DeliveryAttempt, bool) {
+	attempt := nightfury.DeliveryAttempt{At: time.Now()}
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, false
+	}
+	request = request.WithContext(d.ctx)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, notification.Event)
+	request.Header.Set(DeliveryHeader, notification.ID)
+	request.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, true
+	}
+	_ = response.Body.Close()
+	attempt.StatusCode = response.StatusCode
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return attempt, false
+	}
+	attempt.Error = fmt.Sprintf("unexpected status %v", response.Status)
+	return attempt, response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+}
+
+// Sign returns the signature of the body, the hex encoded HMAC-SHA256 of the body keyed by the secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,223 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"github.com/boothgames/nightfury/pkg/db"
+	"github.com/boothgames/nightfury/pkg/internal/mocks/db"
+	"github.com/boothgames/nightfury/pkg/nightfury"
+	"github.com/boothgames/nightfury/pkg/webhook"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver records the requests posted to it and answers them with the given statuses, the last one repeated
+type receiver struct {
+	lock     sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	body, _ := ioutil.ReadAll(request.Body)
+	r.requests = append(r.requests, request)
+	r.bodies = append(r.bodies, body)
+	status := r.statuses[0]
+	if len(r.statuses) > 1 {
+		r.statuses = r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) count() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.requests)
+}
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		webhook.Sign("key", []byte("The quick brown fox jumps over the lazy dog")))
+}
+
+func TestDeliver(t *testing.T) {
+	at := time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+	client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed})
+	notification := nightfury.NewNotification(nightfury.ClientCompletedNotification, client, at)
+	dispatcher := webhook.NewDispatcher(time.Second, 3, time.Millisecond)
+
+	t.Run("should post the signed notification", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().Save("deliveries/prize-desk", gomock.Any()).Return(nil)
+		target := &receiver{statuses: []int{http.StatusOK}}
+		server := httptest.NewServer(target)
+		defer server.Close()
+		hook := nightfury.Webhook{Name: "prize-desk", URL: server.URL, Secret: "secret"}
+
+		delivery := dispatcher.Deliver(repository, hook, notification)
+
+		assert.True(t, delivery.Delivered)
+		assert.Len(t, delivery.Attempts, 1)
+		request := target.requests[0]
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+		assert.Equal(t, nightfury.ClientCompletedNotification, request.Header.Get(webhook.EventHeader))
+		assert.Equal(t, notification.ID, request.Header.Get(webhook.DeliveryHeader))
+		assert.Equal(t, webhook.Sign("secret", target.bodies[0]), request.Header.Get(webhook.SignatureHeader))
+		actual := nightfury.Notification{}
+		_ = json.Unmarshal(target.bodies[0], &actual)
+		assert.Equal(t, "kiosk-1", actual.Client)
+		assert.Equal(t, nightfury.Completed, actual.Status)
+	})
+
+	t.Run("should retry failed requests and log every attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		var logged []nightfury.Delivery
+		repository.EXPECT().Save("deliveries/prize-desk", gomock.Any()).Times(2).DoAndReturn(func(bucketName string, model db.Model) error {
+			logged = append(logged, model.(nightfury.Delivery))
+			return nil
+		})
+		target := &receiver{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}}
+		server := httptest.NewServer(target)
+		defer server.Close()
+
+		delivery := dispatcher.Deliver(repository, nightfury.Webhook{Name: "prize-desk", URL: server.URL}, notification)
+
+		assert.True(t, delivery.Delivered)
+		if assert.Len(t, delivery.Attempts, 2) {
+			assert.Equal(t, http.StatusServiceUnavailable, delivery.Attempts[0].StatusCode)
+			assert.Equal(t, "unexpected status 503 Service Unavailable", delivery.Attempts[0].Error)
+			assert.Equal(t, http.StatusOK, delivery.Attempts[1].StatusCode)
+		}
+		assert.False(t, logged[0].Delivered)
+		assert.Equal(t, delivery, logged[1])
+	})
+
+	t.Run("should give up once the attempts run out", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().Save("deliveries/prize-desk", gomock.Any()).Times(3).Return(nil)
+		target := &receiver{statuses: []int{http.StatusInternalServerError}}
+		server := httptest.NewServer(target)
+		defer server.Close()
+
+		delivery := dispatcher.Deliver(repository, nightfury.Webhook{Name: "prize-desk", URL: server.URL}, notification)
+
+		assert.False(t, delivery.Delivered)
+		assert.Len(t, delivery.Attempts, 3)
+		assert.Equal(t, 3, target.count())
+	})
+
+	t.Run("should not retry rejected requests", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().Save("deliveries/prize-desk", gomock.Any()).Return(nil)
+		target := &receiver{statuses: []int{http.StatusBadRequest}}
+		server := httptest.NewServer(target)
+		defer server.Close()
+
+		delivery := dispatcher.Deliver(repository, nightfury.Webhook{Name: "prize-desk", URL: server.URL}, notification)
+
+		assert.False(t, delivery.Delivered)
+		assert.Len(t, delivery.Attempts, 1)
+	})
+}
+
+func TestDispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	target := &receiver{statuses: []int{http.StatusOK}}
+	server := httptest.NewServer(target)
+	defer server.Close()
+	webhooks := []nightfury.Webhook{
+		{Name: "prize-desk", URL: server.URL, Events: []string{nightfury.ClientCompletedNotification}},
+		{Name: "chat-bot", URL: server.URL},
+		{Name: "resets", URL: server.URL, Events: []string{nightfury.ClientResetNotification}},
+	}
+	repository := mocks.NewMockRepository(ctrl)
+	repository.EXPECT().FetchAll("webhooks", gomock.Any()).DoAndReturn(
+		func(bucketName string, modelFn func(data []byte) (db.Model, error)) (interface{}, error) {
+			models := map[string]interface{}{}
+			for _, hook := range webhooks {
+				data, _ := json.Marshal(hook)
+				model, _ := modelFn(data)
+				models[hook.ID()] = model
+			}
+			return models, nil
+		})
+	repository.EXPECT().Save("deliveries/prize-desk", gomock.Any()).Return(nil)
+	repository.EXPECT().Save("deliveries/chat-bot", gomock.Any()).Return(nil)
+	dispatcher := webhook.NewDispatcher(time.Second, 3, time.Millisecond)
+	client := nightfury.NewClient("kiosk-1", true, nightfury.GameStatus{Name: "snakes", Status: nightfury.Completed})
+	notification := nightfury.NewNotification(nightfury.ClientCompletedNotification, client, time.Now())
+
+	dispatcher.Dispatch(repository, notification)
+	delete(client.GameStatuses, "snakes")
+	dispatcher.Wait()
+
+	assert.Equal(t, 2, target.count())
+	for _, body := range target.bodies {
+		actual := nightfury.Notification{}
+		_ = json.Unmarshal(body, &actual)
+		assert.Contains(t, actual.Games, "snakes")
+	}
+}
+
+func TestDispatcherDrain(t *testing.T) {
+	dispatch := func(t *testing.T, dispatcher webhook.Dispatcher, url string) *gomock.Controller {
+		ctrl := gomock.NewController(t)
+		repository := mocks.NewMockRepository(ctrl)
+		repository.EXPECT().FetchAll("webhooks", gomock.Any()).DoAndReturn(
+			func(bucketName string, modelFn func(data []byte) (db.Model, error)) (interface{}, error) {
+				data, _ := json.Marshal(nightfury.Webhook{Name: "prize-desk", URL: url})
+				model, _ := modelFn(data)
+				return map[string]interface{}{model.ID(): model}, nil
+			})
+		repository.EXPECT().Save("deliveries/prize-desk", gomock.Any()).Return(nil)
+		dispatcher.Dispatch(repository, nightfury.NewNotification(nightfury.ClientResetNotification, nightfury.NewClient("kiosk-1", true), time.Now()))
+		return ctrl
+	}
+
+	t.Run("should stop retrying failed deliveries", func(t *testing.T) {
+		target := &receiver{statuses: []int{http.StatusServiceUnavailable}}
+		server := httptest.NewServer(target)
+		defer server.Close()
+		dispatcher := webhook.NewDispatcher(time.Second, 5, time.Hour)
+		ctrl := dispatch(t, dispatcher, server.URL)
+		defer ctrl.Finish()
+		for deadline := time.Now().Add(time.Second); target.count() == 0 && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+
+		assert.NoError(t, dispatcher.Drain(time.Second))
+		assert.Equal(t, 1, target.count())
+	})
+
+	t.Run("should abort the deliveries still going on after timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+		dispatcher := webhook.NewDispatcher(time.Minute, 1, time.Millisecond)
+		ctrl := dispatch(t, dispatcher, server.URL)
+		defer ctrl.Finish()
+
+		assert.EqualError(t, dispatcher.Drain(10*time.Millisecond), "webhook deliveries still pending after 10ms were dropped")
+		assert.NoError(t, dispatcher.Drain(time.Second))
+	})
+}